@@ -0,0 +1,136 @@
+package structomancer
+
+import (
+	"sort"
+	"strings"
+)
+
+type (
+	// Decides what happens when the map being decoded contains more than one of a field's names (its
+	// nickname and/or its aliases).  See DecodeOptions.AliasConflicts.
+	AliasPolicy int
+
+	keyMatch struct {
+		key  string
+		rank int
+	}
+)
+
+const (
+	// Return an AliasConflictError.
+	AliasConflictIsError AliasPolicy = iota
+
+	// Use the value of the field's nickname, or of whichever of its aliases is listed first.
+	PreferNickname
+
+	// Use the value of whichever of the field's aliases is listed first, even if its nickname is also
+	// present.
+	PreferAlias
+)
+
+// Parses the "alias" flag, which lists the other names a field accepts when decoding, separated by
+// "|" (i.e. `api:"userName, alias=user_name|username"`).
+func parseAliases(t tag) []string {
+	flag, hasAliases := t.FlagValue("alias")
+	if !hasAliases {
+		return nil
+	}
+
+	var aliases []string
+	for _, alias := range strings.Split(flag, "|") {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
+
+// Matches each of `keys` to a field of `s`, returning the key to decode each matched field from.
+// Unknown keys are reported with unknownKey, and fields named by more than one key are resolved
+// according to the AliasConflicts option.
+func (d *decodeState) resolveKeys(s *structSpec, keys []string) (map[string]string, error) {
+	best := make(map[string]keyMatch, len(keys))
+	named := make(map[string][]keyMatch)
+
+	for _, key := range keys {
+		fname, rank, isKnown := d.opts.KeyMatcher.match(s, key)
+		if !isKnown {
+			d.unknownKey(s, key)
+			continue
+		}
+
+		if rank != rankNormalized {
+			named[fname] = append(named[fname], keyMatch{key: key, rank: rank})
+		}
+
+		// ties between keys matched by a KeyMatcher are broken alphabetically, so the result doesn't
+		// depend on map iteration order
+		if cur, exists := best[fname]; !exists || rank < cur.rank || (rank == cur.rank && key < cur.key) {
+			best[fname] = keyMatch{key: key, rank: rank}
+		}
+	}
+
+	for _, fname := range s.FieldNames() {
+		matches := named[fname]
+		if len(matches) < 2 {
+			continue
+		}
+
+		switch d.opts.AliasConflicts {
+		case PreferNickname:
+			// `best` already holds the lowest-ranked key
+
+		case PreferAlias:
+			for _, m := range matches {
+				if m.rank != rankNickname && (best[fname].rank == rankNickname || m.rank < best[fname].rank) {
+					best[fname] = m
+				}
+			}
+
+		default:
+			conflicting := make([]string, len(matches))
+			for i, m := range matches {
+				conflicting[i] = m.key
+			}
+			sort.Strings(conflicting)
+
+			delete(best, fname)
+			d.pushField(fname)
+			err := d.fail(&AliasConflictError{Path: d.currentPath(), Field: fname, Keys: conflicting})
+			d.pop()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	chosen := make(map[string]string, len(best))
+	for fname, m := range best {
+		chosen[fname] = m.key
+	}
+	return chosen, nil
+}
+
+// Returns true if `cur` should replace `prev` as the key that the field `fname` is decoded from, when
+// keys arrive one at a time.
+func (d *decodeState) replacesKey(fname string, prev, cur keyMatch) (bool, error) {
+	if prev.rank == rankNormalized || cur.rank == rankNormalized || prev.key == cur.key {
+		// keys matched by a KeyMatcher never replace exact ones, and otherwise the last key wins
+		return cur.rank <= prev.rank, nil
+	}
+
+	switch d.opts.AliasConflicts {
+	case PreferNickname:
+		return cur.rank < prev.rank, nil
+	case PreferAlias:
+		return cur.rank != rankNickname && (prev.rank == rankNickname || cur.rank < prev.rank), nil
+	default:
+		conflicting := []string{prev.key, cur.key}
+		sort.Strings(conflicting)
+
+		d.pushField(fname)
+		err := d.fail(&AliasConflictError{Path: d.currentPath(), Field: fname, Keys: conflicting})
+		d.pop()
+		return false, err
+	}
+}
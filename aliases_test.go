@@ -0,0 +1,90 @@
+package structomancer_test
+
+import (
+	"errors"
+
+	"github.com/brynbellomy/go-structomancer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Aliases", func() {
+	type Contact struct {
+		Email string `xyzzy:"email, alias=mail"`
+	}
+
+	type User struct {
+		UserName string    `xyzzy:"userName, required, alias=user_name|username"`
+		Contacts []Contact `xyzzy:"contacts"`
+	}
+
+	decode := func(opts structomancer.DecodeOptions, fields map[string]interface{}) (*User, error) {
+		z := structomancer.New(&User{}, "xyzzy")
+		z.SetDecodeOptions(opts)
+		s, err := z.MapToStruct(fields)
+		if s == nil {
+			return nil, err
+		}
+		return s.(*User), err
+	}
+
+	It("should accept any alias when decoding, including in nested structs", func() {
+		u, err := decode(structomancer.DecodeOptions{}, map[string]interface{}{
+			"username": "keith",
+			"contacts": []interface{}{map[string]interface{}{"mail": "keith@example.com"}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(u).To(Equal(&User{UserName: "keith", Contacts: []Contact{{Email: "keith@example.com"}}}))
+	})
+
+	It("should accept aliases in SetFieldValue", func() {
+		z := structomancer.New(&User{}, "xyzzy")
+		u := &User{}
+		Expect(z.SetFieldValue(u, "user_name", "keith")).To(Succeed())
+		Expect(u.UserName).To(Equal("keith"))
+	})
+
+	It("should always encode using the nickname", func() {
+		z := structomancer.New(&User{}, "xyzzy")
+		m, err := z.StructToNativeMap(&User{UserName: "keith"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m).To(HaveKey("userName"))
+		Expect(m).NotTo(HaveKey("user_name"))
+	})
+
+	It("should return an AliasConflictError when more than one of a field's names is present", func() {
+		_, err := decode(structomancer.DecodeOptions{}, map[string]interface{}{"userName": "keith", "username": "mick"})
+
+		var conflictErr *structomancer.AliasConflictError
+		Expect(errors.As(err, &conflictErr)).To(BeTrue())
+		Expect(conflictErr.Path).To(Equal("userName"))
+		Expect(conflictErr.Keys).To(Equal([]string{"userName", "username"}))
+	})
+
+	It("should resolve conflicts according to the AliasConflicts option", func() {
+		fields := map[string]interface{}{"userName": "keith", "username": "mick", "user_name": "ronnie"}
+
+		u, err := decode(structomancer.DecodeOptions{AliasConflicts: structomancer.PreferNickname}, fields)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(u.UserName).To(Equal("keith"))
+
+		u, err = decode(structomancer.DecodeOptions{AliasConflicts: structomancer.PreferAlias}, fields)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(u.UserName).To(Equal("ronnie"))
+	})
+
+	It("should match aliases with the KeyMatcher", func() {
+		u, err := decode(structomancer.DecodeOptions{KeyMatcher: structomancer.MatchCaseInsensitive}, map[string]interface{}{"USER_NAME": "keith"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(u.UserName).To(Equal("keith"))
+	})
+
+	It("should panic when an alias collides with another field's name", func() {
+		type Bad struct {
+			A string `xyzzy:"a, alias=b"`
+			B string `xyzzy:"b"`
+		}
+		Expect(func() { structomancer.New(&Bad{}, "xyzzy") }).To(Panic())
+	})
+})
@@ -0,0 +1,168 @@
+package structomancer
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Options that control how native values are decoded into structs (see
+	// Structomancer.SetDecodeOptions and FromNativeValueWithOptions).
+	DecodeOptions struct {
+		// If true, decoding doesn't stop at the first error.  Instead, every failing value is skipped
+		// (leaving its destination untouched), the partially decoded value is returned, and the
+		// returned error is a DecodeErrors listing everything that went wrong.
+		CollectErrors bool
+
+		// If true, keys that don't match any known field cause an UnknownKeysError listing all of them
+		// (with their full paths) instead of being silently skipped.
+		DisallowUnknownKeys bool
+
+		// If true (and DisallowUnknownKeys is set), keys naming fields that are explicitly ignored by
+		// their tag (i.e. `api:"-"`) are silently skipped rather than reported.
+		AllowIgnoredKeys bool
+
+		// If true, scalars are coerced between types the way you'd expect when decoding query strings
+		// or environment variables: strings are parsed into numbers and bools, numbers and bools are
+		// formatted as strings, and a single value is accepted where a slice is expected.
+		WeaklyTypedInput bool
+
+		// If true, numbers are converted even when they don't fit in their destination type (wrapping
+		// around, losing their sign, or being truncated) instead of causing a ConversionError.
+		AllowLossyNumbers bool
+
+		// Decides which keys match which fields (i.e. MatchCaseInsensitive).  If nil, keys have to
+		// match field nicknames exactly.
+		KeyMatcher *KeyMatcher
+
+		// Decides what happens when a map contains more than one of a field's names (its nickname and
+		// the aliases listed by its "alias" flag).  By default, this is an AliasConflictError.
+		AliasConflicts AliasPolicy
+	}
+
+	// Returned when DecodeOptions.CollectErrors is set and at least one error occurred.  Each error
+	// reports its own path (see ConversionError, UserCoderError, etc.).
+	DecodeErrors []error
+
+	// Tracks the path to the value currently being encoded or decoded, so that errors can report
+	// exactly where they happened (i.e. `structSlice[3].bar[1]`).
+	pathStack struct {
+		path []pathSegment
+	}
+
+	// Tracks the path to the value currently being decoded, along with any errors collected so far.
+	decodeState struct {
+		pathStack
+		opts       DecodeOptions
+		typeCoders map[reflect.Type]typeCoder
+		naming     *NamingStrategy
+		timeFormat string // the "format" flag of the field currently being decoded
+		errs       DecodeErrors
+		unknown    []*UnknownFieldError
+		missing    []string
+	}
+)
+
+func (e DecodeErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return "structomancer: " + strconv.Itoa(len(e)) + " error(s) while decoding: " + strings.Join(msgs, "; ")
+}
+
+// Allows errors.Is and errors.As to find any of the collected errors.
+func (e DecodeErrors) Unwrap() []error {
+	return e
+}
+
+func newDecodeState(opts DecodeOptions, typeCoders map[reflect.Type]typeCoder, naming *NamingStrategy) *decodeState {
+	return &decodeState{opts: opts, typeCoders: typeCoders, naming: naming}
+}
+
+func (d *decodeState) decoderFor(t reflect.Type) (FieldCoderFunc, bool) {
+	return lookupTypeDecoder(d.typeCoders, t)
+}
+
+// Records a decoding error.  When collecting errors, this returns nil so that decoding can continue
+// past the failed value; otherwise, it returns `err` as-is.
+func (d *decodeState) fail(err error) error {
+	if d.opts.CollectErrors {
+		d.errs = append(d.errs, err)
+		return nil
+	}
+	return err
+}
+
+// Returns the error that a top-level decoding call should return, given the error it encountered.
+func (d *decodeState) finish(err error) error {
+	if err != nil {
+		return err
+	}
+
+	if len(d.missing) > 0 {
+		missingErr := &MissingFieldsError{Paths: d.missing}
+		if !d.opts.CollectErrors {
+			return missingErr
+		}
+		d.errs = append(d.errs, missingErr)
+	}
+
+	if len(d.unknown) > 0 {
+		unknownErr := &UnknownKeysError{Fields: d.unknown}
+		if !d.opts.CollectErrors {
+			return unknownErr
+		}
+		d.errs = append(d.errs, unknownErr)
+	}
+
+	if len(d.errs) > 0 {
+		return d.errs
+	}
+	return nil
+}
+
+// Records a key in the input that doesn't match any of the given struct's fields, if the options
+// call for it.
+func (d *decodeState) unknownKey(s *structSpec, key string) {
+	if !d.opts.DisallowUnknownKeys {
+		return
+	}
+
+	ignored := s.IsIgnoredField(key)
+	if ignored && d.opts.AllowIgnoredKeys {
+		return
+	}
+
+	d.pushField(key)
+	d.unknown = append(d.unknown, &UnknownFieldError{Path: d.currentPath(), Field: key, Ignored: ignored})
+	d.pop()
+}
+
+// Records any of the given struct's required fields for which `isPresent` returns false.
+func (d *decodeState) checkRequired(s *structSpec, isPresent func(fname string) bool) {
+	for _, field := range s.RequiredFields() {
+		if !isPresent(field.Nickname()) {
+			d.pushField(field.Nickname())
+			d.missing = append(d.missing, d.currentPath())
+			d.pop()
+		}
+	}
+}
+
+func (p *pathStack) pushField(name string) {
+	p.path = append(p.path, pathSegment{name: name})
+}
+
+func (p *pathStack) pushKey(key string) {
+	p.path = append(p.path, pathSegment{name: key, isKey: true})
+}
+
+func (p *pathStack) pop() {
+	p.path = p.path[:len(p.path)-1]
+}
+
+func (p *pathStack) currentPath() string {
+	return formatPath(p.path)
+}
@@ -0,0 +1,96 @@
+package structomancer_test
+
+import (
+	"time"
+
+	"github.com/brynbellomy/go-structomancer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Defaults", func() {
+	type Limits struct {
+		Burst int `weezy:"burst, default=10"`
+	}
+
+	type Config struct {
+		Host    string        `xyzzy:"host, default=localhost"`
+		Port    uint16        `xyzzy:"port, default=8080"`
+		Debug   bool          `xyzzy:"debug, default=true"`
+		Ratio   float64       `xyzzy:"ratio, default=0.5"`
+		Timeout time.Duration `xyzzy:"timeout, default=1m30s"`
+		Tags    []string      `xyzzy:"tags, default=a|b|c"`
+		Retries *int          `xyzzy:"retries, default=3"`
+		Limits  Limits        `xyzzy:"limits, @tag=weezy"`
+		Plain   string        `xyzzy:"plain"`
+	}
+
+	three := 3
+	defaults := Config{
+		Host:    "localhost",
+		Port:    8080,
+		Debug:   true,
+		Ratio:   0.5,
+		Timeout: 90 * time.Second,
+		Tags:    []string{"a", "b", "c"},
+		Retries: &three,
+		Limits:  Limits{Burst: 10},
+	}
+
+	z := structomancer.New(Config{}, tagName)
+
+	It("should expose the parsed default on the FieldSpec", func() {
+		def, hasDefault := z.Field("port").Default()
+		Expect(hasDefault).To(BeTrue())
+		Expect(def.Interface()).To(Equal(uint16(8080)))
+
+		_, hasDefault = z.Field("plain").Default()
+		Expect(hasDefault).To(BeFalse())
+	})
+
+	It("should apply defaults to missing or nil keys when decoding", func() {
+		c, err := z.MapToStruct(map[string]interface{}{"host": nil, "plain": "x"})
+		Expect(err).To(BeNil())
+
+		expected := defaults
+		expected.Plain = "x"
+		Expect(c).To(Equal(expected))
+	})
+
+	It("should let explicit zero values override defaults", func() {
+		c, err := z.MapToStruct(map[string]interface{}{"debug": false, "limits": map[string]interface{}{}})
+		Expect(err).To(BeNil())
+		Expect(c.(Config).Debug).To(BeFalse())
+		Expect(c.(Config).Limits.Burst).To(Equal(10))
+	})
+
+	It("should fill in the zero fields of an existing struct with .ApplyDefaults", func() {
+		c := &Config{Host: "example.com", Limits: Limits{Burst: 1}}
+		Expect(z.ApplyDefaults(c)).To(Succeed())
+
+		expected := defaults
+		expected.Host = "example.com"
+		expected.Limits.Burst = 1
+		Expect(c).To(Equal(&expected))
+	})
+
+	It("should give every struct its own copy of slice and pointer defaults", func() {
+		c, err := z.MapToStruct(map[string]interface{}{})
+		Expect(err).To(BeNil())
+		c.(Config).Tags[0] = "changed"
+		*c.(Config).Retries = 100
+
+		c, err = z.MapToStruct(map[string]interface{}{})
+		Expect(err).To(BeNil())
+		Expect(c.(Config).Tags).To(Equal([]string{"a", "b", "c"}))
+		Expect(*c.(Config).Retries).To(Equal(3))
+	})
+
+	It("should panic when a default can't be parsed", func() {
+		type Bad struct {
+			N int `xyzzy:"n, default=abc"`
+		}
+		Expect(func() { structomancer.New(Bad{}, tagName) }).To(Panic())
+	})
+})
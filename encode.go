@@ -0,0 +1,57 @@
+package structomancer
+
+import "reflect"
+
+type (
+	// Carries the per-instance type coders of the Structomancer that started an encoding operation
+	// (and its naming strategy) down into any nested structs it encounters, along with the "format"
+	// flag of the field currently being encoded.
+	encodeState struct {
+		pathStack
+		typeCoders map[reflect.Type]typeCoder
+		naming     *NamingStrategy
+		timeFormat string
+	}
+)
+
+func newEncodeState(typeCoders map[reflect.Type]typeCoder, naming *NamingStrategy) *encodeState {
+	return &encodeState{typeCoders: typeCoders, naming: naming}
+}
+
+// Converts `v` (the value of `field`, or something inside of it, or of the struct itself if `field`
+// is nil) to a native Go value, just as StructToNativeMap would: using this Structomancer's type
+// coders and naming strategy, and the field's "format" flag and "@tag" subtag.  Field encoders aren't
+// applied (see FieldEncoder).
+func (z *Structomancer) NativeValue(v reflect.Value, field *FieldSpec) (reflect.Value, error) {
+	e := z.newEncodeState()
+	subtag := z.tagName
+	if field != nil {
+		e.timeFormat = field.timeFormat
+		subtag = field.Subtag()
+	}
+	return e.toNativeValue(v, subtag)
+}
+
+// Returns true if values of type `t` aren't encoded according to their kind, because a type coder
+// is registered for them (globally or with this Structomancer), because they're time.Times or
+// time.Durations, or because they implement encoding.TextMarshaler or json.Marshaler.  Use
+// NativeValue to encode them.
+func (z *Structomancer) HasCustomEncoding(t reflect.Type) bool {
+	if _, exists := lookupTypeEncoder(z.typeCoders, t); exists {
+		return true
+	} else if t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface {
+		return false
+	}
+
+	ptr := reflect.PtrTo(t)
+	return t == timeType || t == durationType ||
+		ptr.Implements(textMarshalerType) || ptr.Implements(jsonMarshalerType)
+}
+
+func (z *Structomancer) newEncodeState() *encodeState {
+	return newEncodeState(z.typeCoders, z.naming)
+}
+
+func (e *encodeState) encoderFor(t reflect.Type) (FieldCoderFunc, bool) {
+	return lookupTypeEncoder(e.typeCoders, t)
+}
@@ -0,0 +1,217 @@
+package structomancer
+
+import (
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Populates the struct pointed to by `aStruct` from the process's environment variables.  See
+// LoadEnvFrom.
+func (z *Structomancer) LoadEnv(aStruct interface{}, prefix string) error {
+	return z.LoadEnvFrom(aStruct, prefix, environ())
+}
+
+// Populates the struct pointed to by `aStruct` from `env`, a map of environment variable names to
+// values.  Each field is read from the variable named by its nickname, upper-cased (with anything
+// other than letters and digits replaced by "_") and joined to `prefix` with "_", i.e. `APP_PORT`.
+// Nested structs' fields are named using their "@tag" subtags and joined to their parent field's
+// variable name, i.e. `APP_DB_HOST`.
+//
+// Values are parsed as though the WeaklyTypedInput decode option were set, and slices are split on
+// the field's "sep" flag (or on "," if it doesn't have one).  Fields without a variable keep their
+// current values (or get their defaults, if they're zero).  If any required field's variable is
+// missing, a MissingFieldsError lists the missing variables' names.
+func (z *Structomancer) LoadEnvFrom(aStruct interface{}, prefix string, env map[string]string) error {
+	sv := reflect.ValueOf(aStruct)
+	if !sv.IsValid() || sv.Kind() != reflect.Ptr || sv.IsNil() {
+		return &NilStructError{Type: z.Type()}
+	} else if !IsStructPtrValue(sv) {
+		return &UnsupportedTypeError{Op: "LoadEnv", Type: sv.Type()}
+	}
+
+	opts := z.decodeOpts
+	opts.WeaklyTypedInput = true
+	d := newDecodeState(opts, z.typeCoders, z.naming)
+
+	applyDefaults(sv.Elem(), z.structSpec, z.tagName)
+	_, err := d.loadEnv(sv.Elem(), z.structSpec, z.tagName, strings.TrimSuffix(prefix, "_"), env, z.fieldDecoders)
+	return d.finish(err)
+}
+
+// Sets the fields of `sv` (described by `s`) from the variables in `env` whose names begin with
+// `prefix`.  Returns true if any variable was found.  Errors are reported under the variables' names.
+func (d *decodeState) loadEnv(sv reflect.Value, s *structSpec, tagName, prefix string, env map[string]string, decoders map[string]FieldCoderFunc) (bool, error) {
+	loaded := false
+
+	for _, fname := range s.FieldNames() {
+		field := s.Field(fname)
+		name := envVarName(prefix, fname)
+		_, hasDecoder := decoders[fname]
+
+		if t := field.Type(); !hasDecoder && d.isEnvStruct(t) {
+			fv := fieldByIndex(sv, field.Index(), true)
+			if !fv.CanSet() {
+				continue
+			}
+
+			subtag := subtagOf(field, tagName)
+			isNew := t.Kind() == reflect.Ptr && fv.IsNil()
+			inner := fv
+			if t.Kind() == reflect.Ptr {
+				t = t.Elem()
+				if isNew {
+					inner = reflect.New(t)
+				}
+				inner = inner.Elem()
+			}
+
+			spec := structSpecForType(t, subtag, d.naming)
+			if isNew {
+				applyDefaults(inner, spec, subtag)
+			}
+
+			numMissing := len(d.missing)
+			found, err := d.loadEnv(inner, spec, subtag, name, env, nil)
+			if err != nil {
+				return loaded, err
+			} else if isNew && !found {
+				// nil struct pointers stay nil (and their required fields aren't missing) unless one
+				// of their variables is set
+				d.missing = d.missing[:numMissing]
+			} else if isNew {
+				fv.Set(inner.Addr())
+			}
+			loaded = loaded || found
+			continue
+		}
+
+		str, isSet := env[name]
+		if !isSet {
+			if field.IsRequired() {
+				d.missing = append(d.missing, name)
+			}
+			continue
+		}
+		loaded = true
+
+		if err := d.setEnvField(sv, field, name, d.envValue(field, str), tagName, decoders[fname]); err != nil {
+			return loaded, err
+		}
+	}
+	return loaded, nil
+}
+
+// Decodes `value` into `field` of `sv`, using `decode` if it isn't nil.
+func (d *decodeState) setEnvField(sv reflect.Value, field *FieldSpec, name string, value interface{}, tagName string, decode FieldCoderFunc) error {
+	fv := fieldByIndex(sv, field.Index(), true)
+	if !fv.CanSet() {
+		return nil
+	}
+
+	d.pushField(name)
+	defer d.pop()
+
+	var v reflect.Value
+	if decode != nil {
+		out, err := decode(value)
+		if err != nil {
+			return d.fail(&UserCoderError{Path: d.currentPath(), Err: err})
+		}
+		v = reflect.ValueOf(out)
+
+	} else {
+		var err error
+		outerFormat := d.timeFormat
+		d.timeFormat = field.timeFormat
+		v, err = d.fromNativeValue(reflect.ValueOf(value), field.Type(), subtagOf(field, tagName))
+		d.timeFormat = outerFormat
+		if err != nil {
+			return err
+		}
+	}
+
+	if v.IsValid() {
+		fv.Set(v)
+	}
+	return nil
+}
+
+// Returns the native value for the variable `str`: a list of strings (split on the field's "sep"
+// flag) if the field holds a slice or array, or else `str` itself.
+func (d *decodeState) envValue(field *FieldSpec, str string) interface{} {
+	t := field.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if (t.Kind() != reflect.Slice && t.Kind() != reflect.Array) || t.Elem().Kind() == reflect.Uint8 || d.hasCustomDecoding(t) {
+		return str
+	} else if str == "" {
+		return []interface{}{}
+	}
+
+	sep, hasSep := field.FlagValue("sep")
+	if !hasSep {
+		sep = ","
+	}
+
+	parts := strings.Split(str, sep)
+	list := make([]interface{}, len(parts))
+	for i := range parts {
+		list[i] = strings.TrimSpace(parts[i])
+	}
+	return list
+}
+
+// Returns true if fields of type `t` are read from one variable per field of their own, rather
+// than from a single variable.
+func (d *decodeState) isEnvStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !d.hasCustomDecoding(t)
+}
+
+// Returns true if values of type `t` are decoded from a single native value by a type coder, or
+// because they're times, durations, big numbers, or encoding.TextUnmarshalers/json.Unmarshalers,
+// rather than according to their kind.
+func (d *decodeState) hasCustomDecoding(t reflect.Type) bool {
+	if _, exists := d.decoderFor(t); exists {
+		return true
+	}
+
+	switch t {
+	case timeType, durationType, bigIntType, bigFloatType, bigRatType:
+		return true
+	}
+
+	ptr := reflect.PtrTo(t)
+	return ptr.Implements(textUnmarshalerType) || ptr.Implements(jsonUnmarshalerType)
+}
+
+// Returns the name of the variable for the field `nickname`, i.e. `APP_MAX_CONNS` for the prefix
+// `APP` and the nickname `max-conns`.
+func envVarName(prefix, nickname string) string {
+	name := strings.Map(func(r rune) rune {
+		if ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper(nickname))
+
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+func environ() map[string]string {
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if i := strings.IndexByte(kv, '='); i > 0 {
+			env[kv[:i]] = kv[i+1:]
+		}
+	}
+	return env
+}
@@ -0,0 +1,112 @@
+package structomancer_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/brynbellomy/go-structomancer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Loading environment variables", func() {
+	type Database struct {
+		Host  string `db:"host, default=localhost"`
+		Port  int    `db:"port, required"`
+		Debug bool   `db:"debug"`
+	}
+
+	type Config struct {
+		Name     string        `env:"name, required"`
+		MaxConns uint          `env:"max-conns"`
+		Timeout  time.Duration `env:"timeout, format=string"`
+		Since    time.Time     `env:"since, format=DateOnly"`
+		Hosts    []string      `env:"hosts"`
+		Ports    []int         `env:"ports, sep=;"`
+		Secret   []byte        `env:"secret"`
+		DB       Database      `env:"db, @tag=db"`
+		Replica  *Database     `env:"replica, @tag=db"`
+		Ignored  string        `env:"-"`
+	}
+
+	It("should read each field from the variable named by its nickname", func() {
+		env := map[string]string{
+			"APP_NAME":      "stones",
+			"APP_MAX_CONNS": "12",
+			"APP_TIMEOUT":   "1m30s",
+			"APP_SINCE":     "1972-05-12",
+			"APP_HOSTS":     "a.example.com, b.example.com",
+			"APP_PORTS":     "80;443",
+			"APP_SECRET":    "shh",
+			"APP_DB_PORT":   "5432",
+			"APP_DB_DEBUG":  "true",
+			"APP_IGNORED":   "x",
+		}
+
+		var c Config
+		z := structomancer.New(&c, "env")
+		Expect(z.LoadEnvFrom(&c, "APP", env)).To(Succeed())
+		Expect(c).To(Equal(Config{
+			Name:     "stones",
+			MaxConns: 12,
+			Timeout:  90 * time.Second,
+			Since:    time.Date(1972, time.May, 12, 0, 0, 0, 0, time.UTC),
+			Hosts:    []string{"a.example.com", "b.example.com"},
+			Ports:    []int{80, 443},
+			Secret:   []byte("shh"),
+			DB:       Database{Host: "localhost", Port: 5432, Debug: true},
+		}))
+	})
+
+	It("should only allocate nested struct pointers when one of their variables is set", func() {
+		c := Config{Name: "keep me", Hosts: []string{"old"}}
+		z := structomancer.New(&c, "env")
+
+		Expect(z.LoadEnvFrom(&c, "", map[string]string{"NAME": "stones", "DB_PORT": "1", "REPLICA_PORT": "2"})).To(Succeed())
+		Expect(c.Name).To(Equal("stones"))
+		Expect(c.Hosts).To(Equal([]string{"old"}))
+		Expect(c.Replica).To(Equal(&Database{Host: "localhost", Port: 2}))
+
+		c.Replica = nil
+		Expect(z.LoadEnvFrom(&c, "", map[string]string{"NAME": "stones", "DB_PORT": "1"})).To(Succeed())
+		Expect(c.Replica).To(BeNil())
+	})
+
+	It("should report missing required variables and unparseable values by variable name", func() {
+		var c Config
+		z := structomancer.New(&c, "env")
+
+		err := z.LoadEnvFrom(&c, "APP_", map[string]string{"APP_DB_HOST": "db"})
+		var missingErr *structomancer.MissingFieldsError
+		Expect(errors.As(err, &missingErr)).To(BeTrue())
+		Expect(missingErr.Paths).To(Equal([]string{"APP_NAME", "APP_DB_PORT"}))
+
+		err = z.LoadEnvFrom(&c, "APP", map[string]string{"APP_NAME": "x", "APP_DB_PORT": "x", "APP_PORTS": "1;two"})
+		var convErr *structomancer.ConversionError
+		Expect(errors.As(err, &convErr)).To(BeTrue())
+		Expect(convErr.Path).To(Equal("APP_PORTS[1]"))
+	})
+
+	It("should honor field decoders", func() {
+		var c Config
+		z := structomancer.New(&c, "env")
+		z.SetFieldDecoder("name", func(x interface{}) (interface{}, error) { return strings.ToUpper(x.(string)), nil })
+
+		Expect(z.LoadEnvFrom(&c, "", map[string]string{"NAME": "stones", "DB_PORT": "1"})).To(Succeed())
+		Expect(c.Name).To(Equal("STONES"))
+	})
+
+	It("should read the process's environment", func() {
+		os.Setenv("STRUCTOMANCER_TEST_NAME", "from the environment")
+		os.Setenv("STRUCTOMANCER_TEST_DB_PORT", "1")
+		defer os.Unsetenv("STRUCTOMANCER_TEST_NAME")
+		defer os.Unsetenv("STRUCTOMANCER_TEST_DB_PORT")
+
+		var c Config
+		Expect(structomancer.New(&c, "env").LoadEnv(&c, "STRUCTOMANCER_TEST")).To(Succeed())
+		Expect(c.Name).To(Equal("from the environment"))
+	})
+})
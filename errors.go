@@ -0,0 +1,170 @@
+package structomancer
+
+import (
+	"reflect"
+	"strings"
+)
+
+type (
+	// Returned when a field nickname doesn't match any of a struct's known fields.
+	UnknownFieldError struct {
+		Path    string // the full path to the field, i.e. `structSlice[3].bar`
+		Field   string // the nickname that wasn't recognized
+		Ignored bool   // true if the name belongs to a field that's explicitly ignored by its tag ("-")
+	}
+
+	// Returned when DecodeOptions.DisallowUnknownKeys is set and the input contains keys that don't
+	// match any known field.
+	UnknownKeysError struct {
+		Fields []*UnknownFieldError
+	}
+
+	// Returned when the input is missing the keys of one or more fields marked with the "required" flag.
+	MissingFieldsError struct {
+		Paths []string // the full path to each missing field, i.e. `structSlice[3].bar`
+	}
+
+	// Returned when a value can't be converted to the type it's being decoded into (or encoded as).
+	ConversionError struct {
+		Path   string // the full path to the value, i.e. `structSlice[3].bar[1]`
+		From   reflect.Type
+		To     reflect.Type
+		Reason string // why a numeric conversion was refused (i.e. "overflow"), if that's what happened
+	}
+
+	// Returned when a map being decoded contains more than one of a field's names (its nickname and
+	// its aliases), unless DecodeOptions.AliasConflicts says which one to use.
+	AliasConflictError struct {
+		Path  string
+		Field string   // the field's nickname
+		Keys  []string // the conflicting keys, sorted
+	}
+
+	// Returned when a value being decoded into a registered interface type names a concrete type that
+	// hasn't been registered (see RegisterInterface and RegisterImplementation).
+	UnregisteredTypeError struct {
+		Path      string
+		Interface reflect.Type
+		Name      string // the value of the discriminator key, or "" if it was missing
+	}
+
+	// Returned when a nil pointer is passed (or found) where a struct is expected.
+	NilStructError struct {
+		Path string // the path at which the nil pointer was found, or "" for the struct argument itself
+		Type reflect.Type
+	}
+
+	// Returned when a value that isn't a struct (or a pointer to one) is passed (or found) where a
+	// struct is expected.
+	UnsupportedTypeError struct {
+		Path string // the path at which the value was found, or "" for the struct argument itself
+		Op   string // the method that was called, i.e. "SetFieldValue"
+		Type reflect.Type
+	}
+
+	// Returned when a path can't be followed, i.e. because an index is out of range or a map key
+	// doesn't exist.
+	PathError struct {
+		Path   string // the path up to (and including) the segment that failed, i.e. `structSlice[5]`
+		Op     string // the method that was called, i.e. "GetFieldValue"
+		Reason string
+	}
+
+	// Wraps an error returned by a user-supplied encoder or decoder (see SetFieldEncoder and
+	// SetFieldDecoder).
+	UserCoderError struct {
+		Path string
+		Err  error
+	}
+)
+
+func (e *UnknownFieldError) Error() string {
+	if e.Ignored {
+		return "structomancer: field '" + e.Path + "' is ignored"
+	}
+	return "structomancer: unknown field '" + e.Path + "'"
+}
+
+func (e *UnknownKeysError) Error() string {
+	keys := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		keys[i] = "'" + f.Path + "'"
+		if f.Ignored {
+			keys[i] += " (ignored)"
+		}
+	}
+	return "structomancer: unknown keys: " + strings.Join(keys, ", ")
+}
+
+// Allows errors.As to find the individual UnknownFieldErrors.
+func (e *UnknownKeysError) Unwrap() []error {
+	errs := make([]error, len(e.Fields))
+	for i, f := range e.Fields {
+		errs[i] = f
+	}
+	return errs
+}
+
+func (e *MissingFieldsError) Error() string {
+	return "structomancer: missing required fields: '" + strings.Join(e.Paths, "', '") + "'"
+}
+
+func (e *ConversionError) Error() string {
+	msg := "structomancer: cannot convert " + typeString(e.From) + " to " + typeString(e.To)
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	if e.Path != "" {
+		msg += " (at '" + e.Path + "')"
+	}
+	return msg
+}
+
+func (e *AliasConflictError) Error() string {
+	return "structomancer: conflicting keys for '" + e.Path + "': '" + strings.Join(e.Keys, "', '") + "'"
+}
+
+func (e *UnregisteredTypeError) Error() string {
+	msg := "structomancer: no implementation of " + typeString(e.Interface) + " registered as '" + e.Name + "'"
+	if e.Path != "" {
+		msg += " (at '" + e.Path + "')"
+	}
+	return msg
+}
+
+func (e *NilStructError) Error() string {
+	if e.Path != "" {
+		return "structomancer: nil " + typeString(e.Type) + " at '" + e.Path + "'"
+	}
+	return "structomancer: struct argument cannot be nil"
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	msg := "structomancer." + e.Op + ": unsupported type '" + typeString(e.Type) + "'"
+	if e.Path != "" {
+		msg += " at '" + e.Path + "'"
+	}
+	return msg
+}
+
+func (e *PathError) Error() string {
+	return "structomancer." + e.Op + ": at '" + e.Path + "': " + e.Reason
+}
+
+func (e *UserCoderError) Error() string {
+	if e.Path == "" {
+		return "structomancer: error calling user coder: " + e.Err.Error()
+	}
+	return "structomancer: error calling user coder for '" + e.Path + "': " + e.Err.Error()
+}
+
+func (e *UserCoderError) Unwrap() error {
+	return e.Err
+}
+
+func typeString(t reflect.Type) string {
+	if t == nil {
+		return "nil"
+	}
+	return t.String()
+}
@@ -0,0 +1,208 @@
+package structomancer_test
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/brynbellomy/go-structomancer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Errors", func() {
+	z := structomancer.New(&Keith{}, tagName)
+
+	It("should return a ConversionError containing the full nested path", func() {
+		_, err := z.MapToStruct(map[string]interface{}{
+			"structSlice": []interface{}{
+				map[string]interface{}{"foo": "ok"},
+				map[string]interface{}{"bar": []interface{}{1, "two"}},
+			},
+		})
+
+		var convErr *structomancer.ConversionError
+		Expect(errors.As(err, &convErr)).To(BeTrue())
+		Expect(convErr.Path).To(Equal("structSlice[1].bar[1]"))
+		Expect(convErr.From).To(Equal(reflect.TypeOf("")))
+		Expect(convErr.To).To(Equal(reflect.TypeOf(B(0))))
+	})
+
+	It("should return an UnknownFieldError for unknown fields", func() {
+		err := z.SetFieldValue(&Keith{}, "nope", 1)
+
+		var unknownErr *structomancer.UnknownFieldError
+		Expect(errors.As(err, &unknownErr)).To(BeTrue())
+		Expect(unknownErr.Field).To(Equal("nope"))
+	})
+
+	It("should return a NilStructError for nil struct arguments", func() {
+		var k *Keith
+		_, err := z.GetFieldValue(k, "name")
+
+		var nilErr *structomancer.NilStructError
+		Expect(errors.As(err, &nilErr)).To(BeTrue())
+
+		_, err = z.GetFieldValue(nil, "name")
+		Expect(errors.As(err, &nilErr)).To(BeTrue())
+
+		err = z.SetFieldValue(nil, "name", "keith")
+		Expect(errors.As(err, &nilErr)).To(BeTrue())
+
+		_, err = z.PointerToField(nil, "name")
+		Expect(errors.As(err, &nilErr)).To(BeTrue())
+	})
+
+	It("should wrap errors returned by user coders in a UserCoderError", func() {
+		oops := errors.New("oops")
+		z := structomancer.New(&Keith{}, tagName)
+		z.SetFieldDecoder("name", func(interface{}) (interface{}, error) { return nil, oops })
+
+		_, err := z.MapToStruct(map[string]interface{}{"name": "keith"})
+
+		var coderErr *structomancer.UserCoderError
+		Expect(errors.As(err, &coderErr)).To(BeTrue())
+		Expect(coderErr.Path).To(Equal("name"))
+		Expect(errors.Is(err, oops)).To(BeTrue())
+	})
+
+	It("should return an UnsupportedTypeError for values that aren't structs", func() {
+		var unsupportedErr *structomancer.UnsupportedTypeError
+
+		_, err := z.GetFieldValue("keith", "name")
+		Expect(errors.As(err, &unsupportedErr)).To(BeTrue())
+		Expect(unsupportedErr.Op).To(Equal("GetFieldValue"))
+		Expect(unsupportedErr.Type).To(Equal(reflect.TypeOf("")))
+
+		n := 1
+		err = z.ApplyDefaults(&n)
+		Expect(errors.As(err, &unsupportedErr)).To(BeTrue())
+		Expect(unsupportedErr.Op).To(Equal("ApplyDefaults"))
+
+		err = z.SetFieldValue(&Keith{}, "name.foo", 1)
+		Expect(errors.As(err, &unsupportedErr)).To(BeTrue())
+		Expect(unsupportedErr.Path).To(Equal("name"))
+		Expect(unsupportedErr.Type).To(Equal(reflect.TypeOf(Name(""))))
+	})
+
+	It("should report the full nested path of errors returned while encoding", func() {
+		oops := errors.New("oops")
+		z := structomancer.New(&Keith{}, tagName)
+		z.RegisterTypeCoder(reflect.TypeOf(B(0)), func(interface{}) (interface{}, error) { return nil, oops }, nil)
+
+		_, err := z.StructToNativeMap(&Keith{StructSlice: []InnerStruct{{"zero", nil}, {"one", []B{1}}}})
+
+		var coderErr *structomancer.UserCoderError
+		Expect(errors.As(err, &coderErr)).To(BeTrue())
+		Expect(coderErr.Path).To(Equal("structSlice[1].bar[0]"))
+		Expect(errors.Is(err, oops)).To(BeTrue())
+	})
+
+	Context("when the CollectErrors decode option is set", func() {
+		It("should keep decoding, and return the partial struct along with every error", func() {
+			z := structomancer.New(&Keith{}, tagName)
+			z.SetDecodeOptions(structomancer.DecodeOptions{CollectErrors: true})
+
+			k, err := z.MapToStruct(map[string]interface{}{
+				"name": "keith",
+				"age":  "old",
+				"inner": map[string]interface{}{
+					"foo": "xyzzy",
+					"bar": []interface{}{1, "two", 3, "four"},
+				},
+			})
+
+			Expect(k).To(Equal(&Keith{Name: "keith", InnerStruct: InnerStruct{"xyzzy", []B{1, 0, 3, 0}}}))
+
+			var errs structomancer.DecodeErrors
+			Expect(errors.As(err, &errs)).To(BeTrue())
+
+			var paths []string
+			for _, e := range errs {
+				paths = append(paths, e.(*structomancer.ConversionError).Path)
+			}
+			Expect(paths).To(ConsistOf("age", "inner.bar[1]", "inner.bar[3]"))
+		})
+	})
+
+	Context("when the DisallowUnknownKeys decode option is set", func() {
+		type Secretive struct {
+			Name   string      `xyzzy:"name"`
+			Secret string      `xyzzy:"-"`
+			Inner  InnerStruct `xyzzy:"inner, @tag=weezy"`
+		}
+
+		input := map[string]interface{}{
+			"name":   "keith",
+			"Secret": "shh",
+			"bogus":  1,
+			"inner":  map[string]interface{}{"foo": "xyzzy", "baz": 2},
+		}
+
+		It("should return an UnknownKeysError listing every unknown key's path", func() {
+			z := structomancer.New(&Secretive{}, tagName)
+			z.SetDecodeOptions(structomancer.DecodeOptions{DisallowUnknownKeys: true})
+
+			_, err := z.MapToStruct(input)
+
+			var unknownErr *structomancer.UnknownKeysError
+			Expect(errors.As(err, &unknownErr)).To(BeTrue())
+
+			ignored := map[string]bool{}
+			for _, f := range unknownErr.Fields {
+				ignored[f.Path] = f.Ignored
+			}
+			Expect(ignored).To(Equal(map[string]bool{"Secret": true, "bogus": false, "inner.baz": false}))
+		})
+
+		It("should tolerate explicitly ignored fields when AllowIgnoredKeys is set", func() {
+			z := structomancer.New(&Secretive{}, tagName)
+			z.SetDecodeOptions(structomancer.DecodeOptions{DisallowUnknownKeys: true, AllowIgnoredKeys: true})
+
+			_, err := z.MapToStruct(input)
+
+			var unknownErr *structomancer.UnknownKeysError
+			Expect(errors.As(err, &unknownErr)).To(BeTrue())
+			Expect(unknownErr.Fields).To(HaveLen(2))
+		})
+	})
+
+	Context("when decoding a struct with \"required\" fields", func() {
+		type Item struct {
+			SKU string `weezy:"sku, required"`
+			Qty int    `weezy:"qty"`
+		}
+
+		type Order struct {
+			ID    string `xyzzy:"id, required"`
+			Note  string `xyzzy:"note, required"`
+			Items []Item `xyzzy:"items, @tag=weezy"`
+		}
+
+		z := structomancer.New(&Order{}, tagName)
+
+		It("should expose the flag on the FieldSpec", func() {
+			Expect(z.Field("id").IsRequired()).To(BeTrue())
+			Expect(z.Field("items").IsRequired()).To(BeFalse())
+		})
+
+		It("should return a MissingFieldsError naming every missing field at every nesting level", func() {
+			_, err := z.MapToStruct(map[string]interface{}{
+				"note": nil,
+				"items": []interface{}{
+					map[string]interface{}{"sku": "a"},
+					map[string]interface{}{"qty": 2},
+				},
+			})
+
+			var missingErr *structomancer.MissingFieldsError
+			Expect(errors.As(err, &missingErr)).To(BeTrue())
+			Expect(missingErr.Paths).To(ConsistOf("id", "note", "items[1].sku"))
+		})
+
+		It("should succeed when every required field is present", func() {
+			_, err := z.MapToStruct(map[string]interface{}{"id": "1", "note": ""})
+			Expect(err).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,157 @@
+package formcodec
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/brynbellomy/go-structomancer"
+)
+
+// Decodes `form` into the struct pointed to by `v`, using the tag name `tagName`.  See Codec.Decode.
+func Decode(form url.Values, v interface{}, tagName string, opts ...structomancer.Option) error {
+	if _, err := destValue(v); err != nil {
+		return err
+	}
+	return NewCodec(structomancer.New(v, tagName, opts...)).Decode(form, v)
+}
+
+// Decodes `form` into the struct pointed to by `v`, replacing its previous contents.  Values are
+// parsed from strings as though the WeaklyTypedInput decode option were set.  Defaults, required
+// fields and the Codec's other decode options work just as they do for MapToStruct.
+func (c *Codec) Decode(form url.Values, v interface{}) error {
+	rv, err := destValue(v)
+	if err != nil {
+		return err
+	} else if indirect(rv.Type()) != indirect(c.z.Type()) {
+		return &structomancer.UnsupportedTypeError{Op: "Decode", Type: rv.Type()}
+	}
+
+	fields, err := c.buildFields(form)
+	if err != nil {
+		return err
+	}
+
+	opts := c.z.DecodeOptions()
+	opts.WeaklyTypedInput = true
+
+	result, err := c.z.WithDecodeOptions(opts).MapToStructV(fields)
+	if result.IsValid() {
+		dest := rv.Elem()
+		for result.Type() != dest.Type() && result.Kind() == reflect.Ptr {
+			result = result.Elem()
+		}
+		dest.Set(result)
+	}
+	return err
+}
+
+// Returns `v` as a reflect.Value, or an error if it isn't a non-nil pointer to a struct (or to a
+// struct pointer).
+func destValue(v interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+		return reflect.Value{}, &structomancer.NilStructError{Type: reflect.TypeOf(v)}
+	} else if rv.Kind() != reflect.Ptr || indirect(rv.Type()).Kind() != reflect.Struct {
+		return reflect.Value{}, &structomancer.UnsupportedTypeError{Op: "Decode", Type: rv.Type()}
+	}
+	return rv, nil
+}
+
+// Assembles the values in `form` into the nested maps and slices that MapToStruct expects, using the
+// types of the fields they're headed for to tell slice indices from map keys.
+func (c *Codec) buildFields(form url.Values) (map[string]interface{}, error) {
+	keys := make([]string, 0, len(form))
+	for key := range form {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fields := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		segs, err := splitKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		node, err := c.insert(fields[segs[0]], c.rootField(segs[0]), segs[1:], form[key])
+		if err != nil {
+			return nil, fmt.Errorf("formcodec: bad key '%v': %v", key, err)
+		}
+		fields[segs[0]] = node
+	}
+	return fields, nil
+}
+
+// Places `values` at `segs` beneath `node` (which is nil if nothing has been placed there yet), and
+// returns the updated node.
+func (c *Codec) insert(node interface{}, s slot, segs []string, values []string) (interface{}, error) {
+	if len(segs) == 0 {
+		if node != nil {
+			return nil, fmt.Errorf("conflicts with another key")
+		}
+		return c.leaf(s, values), nil
+	}
+
+	if c.isList(s) {
+		list, isList := node.([]interface{})
+		if node != nil && !isList {
+			return nil, fmt.Errorf("conflicts with another key")
+		}
+
+		idx, err := strconv.Atoi(segs[0])
+		if err != nil || idx < 0 {
+			return nil, fmt.Errorf("bad index '%v'", segs[0])
+		} else if idx > maxIndex {
+			return nil, fmt.Errorf("index %v is larger than the maximum (%v)", idx, maxIndex)
+		}
+
+		for len(list) <= idx {
+			list = append(list, nil)
+		}
+
+		elem, err := c.insert(list[idx], c.elem(s), segs[1:], values)
+		if err != nil {
+			return nil, err
+		}
+		list[idx] = elem
+		return list, nil
+	}
+
+	m, isMap := node.(map[string]interface{})
+	if node != nil && !isMap {
+		return nil, fmt.Errorf("conflicts with another key")
+	} else if m == nil {
+		m = make(map[string]interface{})
+	}
+
+	child := c.elem(s)
+	if c.isStruct(s) {
+		child = c.field(s, segs[0])
+	}
+
+	elem, err := c.insert(m[segs[0]], child, segs[1:], values)
+	if err != nil {
+		return nil, err
+	}
+	m[segs[0]] = elem
+	return m, nil
+}
+
+// Returns the value to decode into `s` given the form values for its key: a list if `s` holds a
+// slice or there's more than one value, or else the lone value.
+func (c *Codec) leaf(s slot, values []string) interface{} {
+	if len(values) == 0 {
+		return nil
+	} else if len(values) == 1 && !c.isList(s) {
+		return values[0]
+	}
+
+	list := make([]interface{}, len(values))
+	for i := range values {
+		list[i] = values[i]
+	}
+	return list
+}
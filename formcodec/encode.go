@@ -0,0 +1,121 @@
+package formcodec
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+
+	"github.com/brynbellomy/go-structomancer"
+)
+
+// Returns the url.Values encoding of `v` (a struct or a pointer to one), using the tag name `tagName`.
+// See Codec.Encode.
+func Encode(v interface{}, tagName string, opts ...structomancer.Option) (url.Values, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return nil, &structomancer.NilStructError{}
+	} else if indirect(rv.Type()).Kind() != reflect.Struct {
+		return nil, &structomancer.UnsupportedTypeError{Op: "Encode", Type: rv.Type()}
+	}
+	return NewCodec(structomancer.New(v, tagName, opts...)).Encode(v)
+}
+
+// Returns the url.Values encoding of `v` (a struct or a pointer to one).  Fields are converted just
+// as StructToNativeMap would convert them, and then flattened into keys: nested structs' fields are
+// joined with dots, slice elements and map entries are given in brackets, and slices of scalars
+// become repeated keys.
+func (c *Codec) Encode(v interface{}) (url.Values, error) {
+	native, err := c.z.StructToNativeMap(v)
+	if err != nil {
+		return nil, err
+	}
+
+	form := make(url.Values)
+	for fname, nv := range native {
+		if err := c.flatten(form, fname, nv, c.rootField(fname)); err != nil {
+			return nil, err
+		}
+	}
+	return form, nil
+}
+
+// Adds the native value `nv`, which was encoded from `s`, to `form` under `key`.
+func (c *Codec) flatten(form url.Values, key string, nv interface{}, s slot) error {
+	switch nv := nv.(type) {
+	case nil:
+		return nil
+
+	case map[string]interface{}:
+		for k, elem := range nv {
+			var err error
+			if c.isStruct(s) {
+				err = c.flatten(form, key+"."+k, elem, c.field(s, k))
+			} else {
+				err = c.flatten(form, key+"["+k+"]", elem, c.elem(s))
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case []interface{}:
+		if allScalars(nv) {
+			for _, elem := range nv {
+				str, err := formatScalar(key, elem)
+				if err != nil {
+					return err
+				}
+				form.Add(key, str)
+			}
+			return nil
+		}
+
+		for i, elem := range nv {
+			if err := c.flatten(form, key+"["+strconv.Itoa(i)+"]", elem, c.elem(s)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		str, err := formatScalar(key, nv)
+		if err != nil {
+			return err
+		}
+		form.Add(key, str)
+		return nil
+	}
+}
+
+// Returns true if none of the elements of `list` are nil, maps or slices.
+func allScalars(list []interface{}) bool {
+	for _, elem := range list {
+		switch elem.(type) {
+		case nil, map[string]interface{}, []interface{}:
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the string form of the native scalar `x`, which is found at `key`.
+func formatScalar(key string, x interface{}) (string, error) {
+	v := reflect.ValueOf(x)
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), nil
+	default:
+		return "", &structomancer.ConversionError{Path: key, From: v.Type(), To: reflect.TypeOf("")}
+	}
+}
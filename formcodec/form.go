@@ -0,0 +1,156 @@
+// Package formcodec converts structs described by structomancer to and from url.Values, so that query
+// strings and HTML forms can be bound using any tag name.  Nested structs' fields are addressed with
+// dotted keys (`inner.foo`), slice elements and map entries with brackets (`items[0].name`,
+// `ratings[keith]`), and slices of scalars with repeated keys (`tags=a&tags=b`).
+package formcodec
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/brynbellomy/go-structomancer"
+)
+
+type (
+	// Converts structs of the type described by a Structomancer to and from url.Values.
+	Codec struct {
+		z *structomancer.Structomancer
+	}
+
+	// The Go type that a form value is encoded from or decoded into, along with the tag name used for
+	// its fields if it's a struct.  A nil type means that the type isn't known (i.e. the value belongs
+	// to an unknown field, or to a field with its own encoder/decoder).
+	slot struct {
+		t       reflect.Type
+		tagName string
+	}
+)
+
+// Indices in form keys can't be larger than this, so that a single key like `items[999999999]` can't
+// force a huge allocation.
+const maxIndex = 1000
+
+// Returns a Codec for structs of the type described by `z`.  Any field encoders/decoders, type coders,
+// naming strategy and decode options configured on `z` are honored.
+func NewCodec(z *structomancer.Structomancer) *Codec {
+	return &Codec{z: z}
+}
+
+// Returns the slot of the top-level field named `name`.
+func (c *Codec) rootField(name string) slot {
+	field := lookupField(c.z, name)
+	if field == nil {
+		return slot{}
+	} else if _, hasEncoder := c.z.FieldEncoder(field.Nickname()); hasEncoder {
+		return slot{}
+	} else if _, hasDecoder := c.z.FieldDecoder(field.Nickname()); hasDecoder {
+		return slot{}
+	}
+	return slot{t: field.Type(), tagName: field.Subtag()}
+}
+
+// Returns the slot of the field named `name` in the struct held by `s`.
+func (c *Codec) field(s slot, name string) slot {
+	if !c.isStruct(s) {
+		return slot{}
+	}
+
+	field := lookupField(structomancer.NewWithType(indirect(s.t), s.tagName, structomancer.WithNamingStrategy(c.z.NamingStrategy())), name)
+	if field == nil {
+		return slot{}
+	}
+	return slot{t: field.Type(), tagName: field.Subtag()}
+}
+
+// Returns the slot of the elements of the slice, array or map held by `s`.
+func (c *Codec) elem(s slot) slot {
+	t := indirect(s.t)
+	if t == nil {
+		return slot{}
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return slot{t: t.Elem(), tagName: s.tagName}
+	default:
+		return slot{}
+	}
+}
+
+// Returns true if `s` holds a struct whose fields are encoded individually.
+func (c *Codec) isStruct(s slot) bool {
+	t := indirect(s.t)
+	return t != nil && t.Kind() == reflect.Struct && !c.z.HasCustomEncoding(t)
+}
+
+// Returns true if `s` holds a slice or array whose elements are encoded individually.
+func (c *Codec) isList(s slot) bool {
+	t := indirect(s.t)
+	return t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && !c.z.HasCustomEncoding(t)
+}
+
+// Returns the field of `z` named `name`, by its nickname or one of its aliases, or nil.
+func lookupField(z *structomancer.Structomancer, name string) *structomancer.FieldSpec {
+	if field := z.Field(name); field != nil {
+		return field
+	}
+
+	for _, fname := range z.FieldNames() {
+		field := z.Field(fname)
+		for _, alias := range field.Aliases() {
+			if alias == name {
+				return field
+			}
+		}
+	}
+	return nil
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// Splits a form key like `items[0].name` into its components (`items`, `0`, `name`).
+func splitKey(key string) ([]string, error) {
+	var segs []string
+
+	rest := key
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("formcodec: bad key '%v': unterminated '['", key)
+			}
+			segs = append(segs, rest[1:end])
+			rest = rest[end+1:]
+
+		case '.':
+			if len(segs) == 0 {
+				return nil, fmt.Errorf("formcodec: bad key '%v': empty field name", key)
+			}
+			rest = rest[1:]
+			fallthrough
+
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("formcodec: bad key '%v': empty field name", key)
+			}
+			segs = append(segs, rest[:end])
+			rest = rest[end:]
+		}
+	}
+
+	if len(segs) == 0 || key[0] == '[' {
+		return nil, fmt.Errorf("formcodec: bad key '%v': keys must begin with a field name", key)
+	}
+	return segs, nil
+}
@@ -0,0 +1,17 @@
+package formcodec_test
+
+import (
+	"github.com/brynbellomy/ginkgo-reporter"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestFormCodec(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecsWithCustomReporters(t, "FormCodec Suite", []Reporter{
+		&reporter.TerseReporter{Logger: &reporter.DefaultLogger{}},
+	})
+}
@@ -0,0 +1,186 @@
+package formcodec_test
+
+import (
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/brynbellomy/go-structomancer"
+	"github.com/brynbellomy/go-structomancer/formcodec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type Item struct {
+	Name  string `form:"name"`
+	Count int    `form:"count"`
+}
+
+type Address struct {
+	City string `form:"city"`
+	Zip  string `form:"zip"`
+}
+
+type Search struct {
+	Query   string           `form:"q, required"`
+	Page    int              `form:"page, default=1"`
+	Strict  bool             `form:"strict"`
+	Ratio   float64          `form:"ratio"`
+	Since   time.Time        `form:"since, format=DateOnly"`
+	Timeout time.Duration    `form:"timeout, format=string"`
+	Tags    []string         `form:"tags"`
+	IDs     []uint           `form:"ids"`
+	Items   []Item           `form:"items"`
+	Address *Address         `form:"address"`
+	Ratings map[string]int   `form:"ratings"`
+	People  map[string]*Item `form:"people"`
+	Notes   string           `form:"notes, omitempty"`
+}
+
+var _ = Describe("formcodec", func() {
+	It("should decode dotted and bracketed keys, repeated keys and string values", func() {
+		form := url.Values{
+			"q":                 {"stones"},
+			"strict":            {"true"},
+			"ratio":             {"0.5"},
+			"since":             {"1972-05-12"},
+			"timeout":           {"1m30s"},
+			"tags":              {"rock", "blues"},
+			"ids":               {"3"},
+			"items[0].name":     {"Exile"},
+			"items[0].count":    {"2"},
+			"items[1].name":     {"Sticky Fingers"},
+			"address.city":      {"London"},
+			"address[zip]":      {"SW1"},
+			"ratings[keith]":    {"5"},
+			"people[mick].name": {"Mick"},
+		}
+
+		var s Search
+		Expect(formcodec.Decode(form, &s, "form")).To(Succeed())
+		Expect(s).To(Equal(Search{
+			Query:   "stones",
+			Page:    1,
+			Strict:  true,
+			Ratio:   0.5,
+			Since:   time.Date(1972, time.May, 12, 0, 0, 0, 0, time.UTC),
+			Timeout: 90 * time.Second,
+			Tags:    []string{"rock", "blues"},
+			IDs:     []uint{3},
+			Items:   []Item{{Name: "Exile", Count: 2}, {Name: "Sticky Fingers"}},
+			Address: &Address{City: "London", Zip: "SW1"},
+			Ratings: map[string]int{"keith": 5},
+			People:  map[string]*Item{"mick": {Name: "Mick"}},
+		}))
+	})
+
+	It("should leave the elements between given indices zeroed", func() {
+		var s Search
+		Expect(formcodec.Decode(url.Values{"q": {"x"}, "items[2].name": {"Exile"}}, &s, "form")).To(Succeed())
+		Expect(s.Items).To(Equal([]Item{{}, {}, {Name: "Exile"}}))
+	})
+
+	It("should decode what it encodes", func() {
+		s := Search{
+			Query:   "stones",
+			Page:    3,
+			Ratio:   1e-7,
+			Since:   time.Date(1972, time.May, 12, 0, 0, 0, 0, time.UTC),
+			Tags:    []string{"rock"},
+			IDs:     []uint{1, 2},
+			Items:   []Item{{Name: "Exile", Count: 2}},
+			Address: &Address{City: "London"},
+			Ratings: map[string]int{"keith": 5, "mick": 4},
+			People:  map[string]*Item{"bill": {Name: "Bill", Count: 1}},
+		}
+
+		form, err := formcodec.Encode(&s, "form")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(form).To(HaveKeyWithValue("ids", []string{"1", "2"}))
+		Expect(form).To(HaveKeyWithValue("items[0].count", []string{"2"}))
+		Expect(form).To(HaveKeyWithValue("address.city", []string{"London"}))
+		Expect(form).To(HaveKeyWithValue("ratings[mick]", []string{"4"}))
+		Expect(form).To(HaveKeyWithValue("since", []string{"1972-05-12"}))
+		Expect(form).NotTo(HaveKey("notes"))
+
+		var decoded Search
+		Expect(formcodec.Decode(form, &decoded, "form")).To(Succeed())
+		Expect(decoded).To(Equal(s))
+	})
+
+	It("should honor the decode options of the Structomancer it's given without changing them", func() {
+		z := structomancer.New(&Search{}, "form")
+		z.SetDecodeOptions(structomancer.DecodeOptions{DisallowUnknownKeys: true})
+		codec := formcodec.NewCodec(z)
+
+		var s Search
+		err := codec.Decode(url.Values{"q": {"x"}, "address.country": {"UK"}}, &s)
+		var unknownErr *structomancer.UnknownKeysError
+		Expect(errors.As(err, &unknownErr)).To(BeTrue())
+		Expect(unknownErr.Fields[0].Path).To(Equal("address.country"))
+
+		Expect(z.DecodeOptions().WeaklyTypedInput).To(BeFalse())
+	})
+
+	It("should neither encode nor decode unexported fields", func() {
+		type Login struct {
+			User     string    `form:"user"`
+			password string    `form:"password"`
+			expires  time.Time `form:"expires"`
+		}
+
+		form, err := formcodec.Encode(&Login{User: "keith", password: "hunter2", expires: time.Now()}, "form")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(form).To(Equal(url.Values{"user": {"keith"}}))
+
+		var l Login
+		Expect(formcodec.Decode(url.Values{"user": {"keith"}, "password": {"x"}}, &l, "form")).To(Succeed())
+		Expect(l).To(Equal(Login{User: "keith"}))
+	})
+
+	It("should return errors for arguments that aren't structs", func() {
+		var nilErr *structomancer.NilStructError
+		var unsupportedErr *structomancer.UnsupportedTypeError
+		form := url.Values{"q": {"x"}}
+
+		Expect(errors.As(formcodec.Decode(form, nil, "form"), &nilErr)).To(BeTrue())
+		Expect(errors.As(formcodec.Decode(form, (*Search)(nil), "form"), &nilErr)).To(BeTrue())
+
+		var n int
+		Expect(errors.As(formcodec.Decode(form, &n, "form"), &unsupportedErr)).To(BeTrue())
+		Expect(errors.As(formcodec.Decode(form, Search{}, "form"), &unsupportedErr)).To(BeTrue())
+
+		c := formcodec.NewCodec(structomancer.New(&Search{}, "form"))
+		Expect(errors.As(c.Decode(form, &n), &unsupportedErr)).To(BeTrue())
+		Expect(errors.As(c.Decode(form, &Item{}), &unsupportedErr)).To(BeTrue())
+
+		_, err := formcodec.Encode(nil, "form")
+		Expect(errors.As(err, &nilErr)).To(BeTrue())
+		_, err = formcodec.Encode(5, "form")
+		Expect(errors.As(err, &unsupportedErr)).To(BeTrue())
+	})
+
+	It("should report bad values, missing fields and bad keys", func() {
+		var s Search
+
+		err := formcodec.Decode(url.Values{"q": {"x"}, "items[0].count": {"lots"}}, &s, "form")
+		var convErr *structomancer.ConversionError
+		Expect(errors.As(err, &convErr)).To(BeTrue())
+		Expect(convErr.Path).To(Equal("items[0].count"))
+
+		err = formcodec.Decode(url.Values{"q": {"x"}, "page": {"1", "2"}}, &s, "form")
+		Expect(errors.As(err, &convErr)).To(BeTrue())
+		Expect(convErr.Path).To(Equal("page"))
+
+		err = formcodec.Decode(url.Values{"page": {"2"}}, &s, "form")
+		var missingErr *structomancer.MissingFieldsError
+		Expect(errors.As(err, &missingErr)).To(BeTrue())
+		Expect(missingErr.Paths).To(Equal([]string{"q"}))
+
+		Expect(formcodec.Decode(url.Values{"q": {"x"}, "items[x].name": {"a"}}, &s, "form")).NotTo(Succeed())
+		Expect(formcodec.Decode(url.Values{"q": {"x"}, "items[5000].name": {"a"}}, &s, "form")).NotTo(Succeed())
+		Expect(formcodec.Decode(url.Values{"q": {"x"}, "address": {"a"}, "address.city": {"b"}}, &s, "form")).NotTo(Succeed())
+		Expect(formcodec.Decode(url.Values{"q": {"x"}, "[0]": {"a"}}, &s, "form")).NotTo(Succeed())
+	})
+})
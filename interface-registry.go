@@ -0,0 +1,123 @@
+package structomancer
+
+import (
+	"reflect"
+	"sync"
+)
+
+type (
+	interfaceRegistry struct {
+		sync.RWMutex
+		ifaces map[reflect.Type]*interfaceSpec
+	}
+
+	// Describes how values of a registered interface type are (de)serialized: the concrete types
+	// that implement it, and the map key whose value identifies which one a given map holds.
+	interfaceSpec struct {
+		discriminator string
+		byName        map[string]reflect.Type
+		byType        map[reflect.Type]string
+	}
+)
+
+var ifaceRegistry = &interfaceRegistry{ifaces: make(map[reflect.Type]*interfaceSpec)}
+
+// Registers the interface type `ifaceType` for polymorphic (de)serialization.  When a map is decoded
+// into a field (or slice element, map value, etc.) of that type, the value of its `discriminator` key
+// is used to look up the concrete type to decode it into (see RegisterImplementation).  When such a
+// value is encoded with ToNativeValue, the discriminator key is added to the resulting map.
+//
+// For example, `RegisterInterface(reflect.TypeOf((*Shape)(nil)).Elem(), "type")`.
+func RegisterInterface(ifaceType reflect.Type, discriminator string) {
+	if ifaceType.Kind() != reflect.Interface {
+		panic("structomancer: RegisterInterface requires an interface type, got " + ifaceType.String())
+	}
+
+	ifaceRegistry.Lock()
+	defer ifaceRegistry.Unlock()
+
+	ifaceRegistry.ifaces[ifaceType] = &interfaceSpec{
+		discriminator: discriminator,
+		byName:        make(map[string]reflect.Type),
+		byType:        make(map[reflect.Type]string),
+	}
+}
+
+// Registers `concreteType` as an implementation of the interface type `ifaceType` (which must already
+// have been registered with RegisterInterface), identified by the discriminator value `name`.
+func RegisterImplementation(ifaceType reflect.Type, name string, concreteType reflect.Type) {
+	if !concreteType.Implements(ifaceType) {
+		panic("structomancer: " + concreteType.String() + " does not implement " + ifaceType.String())
+	}
+
+	ifaceRegistry.Lock()
+	defer ifaceRegistry.Unlock()
+
+	spec, exists := ifaceRegistry.ifaces[ifaceType]
+	if !exists {
+		panic("structomancer: interface " + ifaceType.String() + " has not been registered")
+	}
+	spec.byName[name] = concreteType
+	spec.byType[concreteType] = name
+}
+
+// Returns the spec for the given interface type, or nil if it hasn't been registered.
+func registeredInterface(ifaceType reflect.Type) *interfaceSpec {
+	ifaceRegistry.RLock()
+	defer ifaceRegistry.RUnlock()
+	return ifaceRegistry.ifaces[ifaceType]
+}
+
+// Returns the discriminator value for the given concrete type.
+func (s *interfaceSpec) nameOf(concreteType reflect.Type) (string, bool) {
+	ifaceRegistry.RLock()
+	defer ifaceRegistry.RUnlock()
+	name, exists := s.byType[concreteType]
+	return name, exists
+}
+
+// Returns the concrete type registered under the given discriminator value.
+func (s *interfaceSpec) typeNamed(name string) (reflect.Type, bool) {
+	ifaceRegistry.RLock()
+	defer ifaceRegistry.RUnlock()
+	t, exists := s.byName[name]
+	return t, exists
+}
+
+// Decodes the map `nv` into the concrete type named by its discriminator key.
+func (d *decodeState) fromNativeInterface(nv reflect.Value, ifaceType reflect.Type, spec *interfaceSpec, subtag string) (reflect.Value, error) {
+	if nv.Type().Key().Kind() != reflect.String {
+		return reflect.Value{}, d.fail(&ConversionError{Path: d.currentPath(), From: nv.Type(), To: ifaceType})
+	}
+
+	discriminatorKey := reflect.ValueOf(spec.discriminator).Convert(nv.Type().Key())
+
+	var name string
+	if nameVal := nv.MapIndex(discriminatorKey); nameVal.IsValid() {
+		name, _ = nameVal.Interface().(string)
+	}
+
+	concreteType, exists := spec.typeNamed(name)
+	if !exists {
+		return reflect.Value{}, d.fail(&UnregisteredTypeError{Path: d.currentPath(), Interface: ifaceType, Name: name})
+	}
+
+	// strip the discriminator (unless the concrete type has a field by that name) so that it isn't
+	// treated as an unknown key
+	structType := concreteType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() == reflect.Struct && structSpecForType(structType, subtag, d.naming).Field(spec.discriminator) == nil {
+		stripped := reflect.MakeMapWithSize(nv.Type(), nv.Len())
+		iter := nv.MapRange()
+		for iter.Next() {
+			if iter.Key().String() != spec.discriminator {
+				stripped.SetMapIndex(iter.Key(), iter.Value())
+			}
+		}
+		nv = stripped
+	}
+
+	return d.fromNativeValue(nv, concreteType, subtag)
+}
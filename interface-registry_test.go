@@ -0,0 +1,95 @@
+package structomancer_test
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/brynbellomy/go-structomancer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type (
+	Shape interface {
+		Area() float64
+	}
+
+	Circle struct {
+		Radius float64 `xyzzy:"radius"`
+	}
+
+	Square struct {
+		Side float64 `xyzzy:"side"`
+	}
+
+	Drawing struct {
+		Main   Shape   `xyzzy:"main"`
+		Shapes []Shape `xyzzy:"shapes"`
+	}
+)
+
+func (c Circle) Area() float64  { return 3 * c.Radius * c.Radius }
+func (s *Square) Area() float64 { return s.Side * s.Side }
+
+var _ = Describe("Interface registry", func() {
+	shapeType := reflect.TypeOf((*Shape)(nil)).Elem()
+	structomancer.RegisterInterface(shapeType, "type")
+	structomancer.RegisterImplementation(shapeType, "circle", reflect.TypeOf(Circle{}))
+	structomancer.RegisterImplementation(shapeType, "square", reflect.TypeOf(&Square{}))
+
+	z := structomancer.New(&Drawing{}, tagName)
+
+	It("should decode maps into the concrete type named by the discriminator key", func() {
+		z := structomancer.New(&Drawing{}, tagName)
+		z.SetDecodeOptions(structomancer.DecodeOptions{DisallowUnknownKeys: true})
+
+		d, err := z.MapToStruct(map[string]interface{}{
+			"main": map[string]interface{}{"type": "circle", "radius": 1.0},
+			"shapes": []interface{}{
+				map[string]interface{}{"type": "square", "side": 2.0},
+				map[string]interface{}{"type": "circle", "radius": 3.0},
+			},
+		})
+		Expect(err).To(BeNil())
+		Expect(d).To(Equal(&Drawing{
+			Main:   Circle{Radius: 1},
+			Shapes: []Shape{&Square{Side: 2}, Circle{Radius: 3}},
+		}))
+	})
+
+	It("should add the discriminator key when encoding", func() {
+		m, err := z.StructToNativeMap(&Drawing{
+			Main:   &Square{Side: 2},
+			Shapes: []Shape{Circle{Radius: 3}},
+		})
+		Expect(err).To(BeNil())
+		Expect(m).To(Equal(map[string]interface{}{
+			"main":   map[string]interface{}{"type": "square", "side": 2.0},
+			"shapes": []interface{}{map[string]interface{}{"type": "circle", "radius": 3.0}},
+		}))
+	})
+
+	It("should return an UnregisteredTypeError for unknown discriminator values", func() {
+		_, err := z.MapToStruct(map[string]interface{}{
+			"shapes": []interface{}{map[string]interface{}{"type": "hexagon"}},
+		})
+
+		var unregErr *structomancer.UnregisteredTypeError
+		Expect(errors.As(err, &unregErr)).To(BeTrue())
+		Expect(unregErr.Name).To(Equal("hexagon"))
+		Expect(unregErr.Path).To(Equal("shapes[0]"))
+	})
+
+	It("should return a ConversionError for values that don't implement the interface", func() {
+		_, err := z.MapToStruct(map[string]interface{}{
+			"shapes": []interface{}{Circle{Radius: 1}, "hexagon"},
+		})
+
+		var convErr *structomancer.ConversionError
+		Expect(errors.As(err, &convErr)).To(BeTrue())
+		Expect(convErr.Path).To(Equal("shapes[1]"))
+		Expect(convErr.From).To(Equal(reflect.TypeOf("")))
+		Expect(convErr.To).To(Equal(shapeType))
+	})
+})
@@ -0,0 +1,120 @@
+package jsoncodec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/brynbellomy/go-structomancer"
+)
+
+type (
+	// Reads JSON objects from an input stream into structs, one per call to Decode.
+	Decoder struct {
+		dec *json.Decoder
+		z   *structomancer.Structomancer
+	}
+)
+
+// Decodes the JSON object in `data` into the struct pointed to by `v`, using the tag name `tagName`.
+func Unmarshal(data []byte, v interface{}, tagName string, opts ...structomancer.Option) error {
+	rv, err := destValue("Unmarshal", v)
+	if err != nil {
+		return err
+	}
+
+	dec := NewDecoder(bytes.NewReader(data), structomancer.NewWithType(rv.Type(), tagName, opts...))
+	if err := dec.Decode(v); err != nil {
+		return err
+	} else if _, err := dec.dec.Token(); err != io.EOF {
+		return errors.New("jsoncodec: invalid character after top-level value")
+	}
+	return nil
+}
+
+// Returns `v` as a reflect.Value, or an error if it isn't a non-nil pointer to a struct (or to a
+// struct pointer).
+func destValue(op string, v interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+		return reflect.Value{}, &structomancer.NilStructError{Type: reflect.TypeOf(v)}
+	} else if rv.Kind() != reflect.Ptr {
+		return reflect.Value{}, fmt.Errorf("jsoncodec.%v: argument must be a non-nil pointer, got %v", op, rv.Type())
+	} else if structType(rv.Type()).Kind() != reflect.Struct {
+		return reflect.Value{}, &structomancer.UnsupportedTypeError{Op: op, Type: rv.Type()}
+	}
+	return rv, nil
+}
+
+// Returns `t` with any pointers stripped off.
+func structType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// Returns a Decoder that reads structs of the type described by `z` from `r`.  Any field decoders,
+// type coders, decode options and naming strategy configured on `z` are honored.
+func NewDecoder(r io.Reader, z *structomancer.Structomancer) *Decoder {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return &Decoder{dec: dec, z: z}
+}
+
+// Reads the next JSON object from the input and stores it in the struct pointed to by `v`, replacing
+// its previous contents.  The object's keys are read one at a time, so the object itself is never
+// held in a map, but each value is decoded into native Go values before being converted to its
+// field's type (so nested objects are).  A JSON null leaves `v` untouched.
+func (dec *Decoder) Decode(v interface{}) error {
+	rv, err := destValue("Decode", v)
+	if err != nil {
+		return err
+	} else if structType(rv.Type()) != structType(dec.z.Type()) {
+		return &structomancer.UnsupportedTypeError{Op: "Decode", Type: rv.Type()}
+	}
+
+	tok, err := dec.dec.Token()
+	if err != nil {
+		return err
+	} else if tok == nil {
+		return nil
+	} else if tok != json.Delim('{') {
+		return fmt.Errorf("jsoncodec: expected an object, got %v", tok)
+	}
+
+	result, err := dec.z.FieldsToStructV(dec.nextField)
+	if result.IsValid() {
+		dest := rv.Elem()
+		for result.Type() != dest.Type() && result.Kind() == reflect.Ptr {
+			result = result.Elem()
+		}
+		dest.Set(result)
+	}
+	return err
+}
+
+// Reads the next key and value of the object being decoded.  Returns false once the end of the
+// object has been reached.
+func (dec *Decoder) nextField() (string, reflect.Value, bool, error) {
+	tok, err := dec.dec.Token()
+	if err != nil {
+		return "", reflect.Value{}, false, err
+	} else if tok == json.Delim('}') {
+		return "", reflect.Value{}, false, nil
+	}
+
+	key, isString := tok.(string)
+	if !isString {
+		return "", reflect.Value{}, false, fmt.Errorf("jsoncodec: expected an object key, got %v", tok)
+	}
+
+	var value interface{}
+	if err := dec.dec.Decode(&value); err != nil {
+		return "", reflect.Value{}, false, err
+	}
+	return key, reflect.ValueOf(value), true, nil
+}
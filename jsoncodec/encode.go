@@ -0,0 +1,363 @@
+// Package jsoncodec reads and writes JSON directly from and to structs described by structomancer,
+// so that any tag name (not just "json") can drive JSON serialization.  Encoding never goes through
+// an intermediate map.  Decoding streams only the top-level object: its keys are read one at a time,
+// but each value (including nested objects and the elements of slices of structs) is first decoded
+// into native Go values, so nested objects are still held in maps briefly.
+package jsoncodec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/brynbellomy/go-structomancer"
+)
+
+type (
+	// Writes JSON values to an output stream, one per call to Encode.
+	Encoder struct {
+		w io.Writer
+		z *structomancer.Structomancer
+	}
+
+	encodeState struct {
+		bytes.Buffer
+		root *structomancer.Structomancer
+	}
+)
+
+// Returns the JSON encoding of `v` (a struct or a pointer to one), using the tag name `tagName`.
+func Marshal(v interface{}, tagName string, opts ...structomancer.Option) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return nil, &structomancer.NilStructError{}
+	} else if !structomancer.IsStructValue(rv) && !structomancer.IsStructPtrValue(rv) {
+		return nil, &structomancer.UnsupportedTypeError{Op: "Marshal", Type: rv.Type()}
+	}
+
+	e := &encodeState{root: structomancer.New(v, tagName, opts...)}
+	if err := e.encodeStruct(reflect.ValueOf(v), e.root); err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+// Returns an Encoder that writes structs of the type described by `z` to `w`.  Any field encoders,
+// type coders and naming strategy configured on `z` are honored.
+func NewEncoder(w io.Writer, z *structomancer.Structomancer) *Encoder {
+	return &Encoder{w: w, z: z}
+}
+
+// Writes the JSON encoding of `v` (a struct or a pointer to one), followed by a newline.
+func (enc *Encoder) Encode(v interface{}) error {
+	e := &encodeState{root: enc.z}
+	if err := e.encodeStruct(reflect.ValueOf(v), enc.z); err != nil {
+		return err
+	}
+	e.WriteByte('\n')
+
+	_, err := enc.w.Write(e.Bytes())
+	return err
+}
+
+// Writes the struct in `v` as a JSON object, using `z` (which must describe its type) to find its
+// fields.
+func (e *encodeState) encodeStruct(v reflect.Value, z *structomancer.Structomancer) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			e.WriteString("null")
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	e.WriteByte('{')
+	first := true
+	for _, fname := range z.FieldNames() {
+		field := z.Field(fname)
+
+		fv, err := z.GetFieldValueV(v, fname)
+		if err != nil {
+			return err
+		} else if field.IsFlagged("omitempty") && structomancer.IsZero(fv) {
+			continue
+		}
+
+		if !first {
+			e.WriteByte(',')
+		}
+		first = false
+		e.writeString(fname)
+		e.WriteByte(':')
+
+		// values returned by field encoders are converted like any other native value
+		if _, hasEncoder := z.FieldEncoder(fname); hasEncoder {
+			err = e.encodeNative(fv, field)
+		} else {
+			err = e.encodeValue(fv, field)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	e.WriteByte('}')
+	return nil
+}
+
+// Writes `v`, the value of `field` (or something inside of it).  Structs, slices, maps and pointers
+// are walked directly; anything that structomancer encodes specially (and interfaces, which may need
+// a discriminator key) goes through structomancer.NativeValue.
+func (e *encodeState) encodeValue(v reflect.Value, field *structomancer.FieldSpec) error {
+	if !v.IsValid() {
+		e.WriteString("null")
+		return nil
+	} else if v.Kind() == reflect.Interface || e.root.HasCustomEncoding(v.Type()) {
+		return e.encodeNative(v, field)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			e.WriteString("null")
+			return nil
+		}
+		return e.encodeValue(v.Elem(), field)
+
+	case reflect.Struct:
+		z := structomancer.NewWithType(v.Type(), field.Subtag(), structomancer.WithNamingStrategy(e.root.NamingStrategy()))
+		return e.encodeStruct(v, z)
+
+	case reflect.Slice, reflect.Array:
+		e.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				e.WriteByte(',')
+			}
+			if err := e.encodeValue(v.Index(i), field); err != nil {
+				return err
+			}
+		}
+		e.WriteByte(']')
+		return nil
+
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		values := make(map[string]reflect.Value, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			nk, err := e.root.NativeValue(iter.Key(), field)
+			if err != nil {
+				return err
+			} else if nk.Kind() != reflect.String {
+				return &structomancer.ConversionError{From: iter.Key().Type(), To: reflect.TypeOf("")}
+			}
+			keys = append(keys, nk.String())
+			values[nk.String()] = iter.Value()
+		}
+		sort.Strings(keys)
+
+		e.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				e.WriteByte(',')
+			}
+			e.writeString(key)
+			e.WriteByte(':')
+			if err := e.encodeValue(values[key], field); err != nil {
+				return err
+			}
+		}
+		e.WriteByte('}')
+		return nil
+
+	default:
+		return e.writeScalar(v)
+	}
+}
+
+// Converts `v` with structomancer.NativeValue and writes the result.
+func (e *encodeState) encodeNative(v reflect.Value, field *structomancer.FieldSpec) error {
+	nv, err := e.root.NativeValue(v, field)
+	if err != nil {
+		return err
+	}
+	return e.writeNative(nv)
+}
+
+// Writes a value made up of native Go types (as returned by structomancer.NativeValue).
+func (e *encodeState) writeNative(v reflect.Value) error {
+	if v.Kind() == reflect.Interface {
+		v = reflect.ValueOf(v.Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.Invalid:
+		e.WriteString("null")
+		return nil
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			e.WriteString("null")
+			return nil
+		}
+		return e.writeNative(v.Elem())
+
+	case reflect.Slice, reflect.Array:
+		e.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				e.WriteByte(',')
+			}
+			if err := e.writeNative(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		e.WriteByte(']')
+		return nil
+
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			if k.Kind() != reflect.String {
+				return &structomancer.ConversionError{From: k.Type(), To: reflect.TypeOf("")}
+			}
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+
+		e.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				e.WriteByte(',')
+			}
+			e.writeString(key)
+			e.WriteByte(':')
+			if err := e.writeNative(v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))); err != nil {
+				return err
+			}
+		}
+		e.WriteByte('}')
+		return nil
+
+	default:
+		return e.writeScalar(v)
+	}
+}
+
+func (e *encodeState) writeScalar(v reflect.Value) error {
+	var scratch [64]byte
+
+	switch v.Kind() {
+	case reflect.Bool:
+		e.Write(strconv.AppendBool(scratch[:0], v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.Write(strconv.AppendInt(scratch[:0], v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.Write(strconv.AppendUint(scratch[:0], v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		return e.writeFloat(v.Float(), v.Type().Bits())
+	case reflect.String:
+		if v.Type() == numberType {
+			// json.Numbers are written as-is (and validated, as the json package does)
+			if !json.Valid([]byte(v.String())) {
+				return fmt.Errorf("jsoncodec: invalid number literal %q", v.String())
+			}
+			e.WriteString(v.String())
+		} else {
+			e.writeString(v.String())
+		}
+	default:
+		return fmt.Errorf("jsoncodec: unsupported type %v", v.Type())
+	}
+	return nil
+}
+
+var numberType = reflect.TypeOf(json.Number(""))
+
+// Writes a float the way the json package does: without an exponent unless it's very large or very
+// small.
+func (e *encodeState) writeFloat(f float64, bits int) error {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return fmt.Errorf("jsoncodec: unsupported value %v", strconv.FormatFloat(f, 'g', -1, bits))
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+
+	var scratch [64]byte
+	b := strconv.AppendFloat(scratch[:0], f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	e.Write(b)
+	return nil
+}
+
+const hex = "0123456789abcdef"
+
+// Writes `s` as a JSON string, escaping quotes, backslashes, control characters, and the characters
+// that are dangerous to embed in HTML (just like the json package).
+func (e *encodeState) writeString(s string) {
+	e.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			e.WriteString(s[start:i])
+			switch b {
+			case '"', '\\':
+				e.WriteByte('\\')
+				e.WriteByte(b)
+			case '\n':
+				e.WriteString(`\n`)
+			case '\r':
+				e.WriteString(`\r`)
+			case '\t':
+				e.WriteString(`\t`)
+			default:
+				e.WriteString(`\u00`)
+				e.WriteByte(hex[b>>4])
+				e.WriteByte(hex[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			e.WriteString(s[start:i])
+			e.WriteString(`\ufffd`)
+			i += size
+			start = i
+			continue
+		} else if r == '\u2028' || r == '\u2029' {
+			e.WriteString(s[start:i])
+			e.WriteString(`\u202`)
+			e.WriteByte(hex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	e.WriteString(s[start:])
+	e.WriteByte('"')
+}
@@ -0,0 +1,17 @@
+package jsoncodec_test
+
+import (
+	"github.com/brynbellomy/ginkgo-reporter"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestJSONCodec(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecsWithCustomReporters(t, "JSONCodec Suite", []Reporter{
+		&reporter.TerseReporter{Logger: &reporter.DefaultLogger{}},
+	})
+}
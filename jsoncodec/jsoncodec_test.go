@@ -0,0 +1,162 @@
+package jsoncodec_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/brynbellomy/go-structomancer"
+	"github.com/brynbellomy/go-structomancer/jsoncodec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type Track struct {
+	Title    string        `weezy:"title"`
+	Duration time.Duration `weezy:"duration, format=string"`
+}
+
+type Album struct {
+	Name     string            `api:"name"`
+	Released time.Time         `api:"released, format=DateOnly"`
+	Tracks   []Track           `api:"tracks, @tag=weezy"`
+	Sleeve   *Track            `api:"sleeve, @tag=weezy, omitempty"`
+	Ratings  map[string]int    `api:"ratings"`
+	Price    float64           `api:"price"`
+	Notes    string            `api:"notes, omitempty"`
+	Label    string            `api:"label, required"`
+	Extra    map[string]string `api:"extra"`
+	Secret   string            `api:"-"`
+}
+
+var _ = Describe("jsoncodec", func() {
+	album := Album{
+		Name:     `Exile on "Main" St. <&>`,
+		Released: time.Date(1972, time.May, 12, 0, 0, 0, 0, time.UTC),
+		Tracks:   []Track{{Title: "Rocks Off", Duration: 271 * time.Second}, {Title: "Rip This Joint", Duration: 142 * time.Second}},
+		Ratings:  map[string]int{"keith": 5, "mick": 4},
+		Price:    1e-7,
+		Label:    "Rolling Stones Records",
+		Extra:    map[string]string{"producer": "Jimmy Miller"},
+		Secret:   "shh",
+	}
+
+	It("should write the same JSON as StructToNativeMap followed by json.Marshal", func() {
+		bs, err := jsoncodec.Marshal(&album, "api")
+		Expect(err).NotTo(HaveOccurred())
+
+		m, err := structomancer.New(&album, "api").StructToNativeMap(&album)
+		Expect(err).NotTo(HaveOccurred())
+		expected, err := json.Marshal(m)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(bs).To(MatchJSON(expected))
+		Expect(string(bs)).To(HavePrefix(`{"name":"Exile on \"Main\" St. \u003c\u0026\u003e","released":"1972-05-12","tracks":[{"title":"Rocks Off","duration":"4m31s"}`))
+	})
+
+	It("should read back what it writes", func() {
+		bs, err := jsoncodec.Marshal(&album, "api")
+		Expect(err).NotTo(HaveOccurred())
+
+		var decoded Album
+		Expect(jsoncodec.Unmarshal(bs, &decoded, "api")).To(Succeed())
+
+		expected := album
+		expected.Secret = ""
+		Expect(decoded).To(Equal(expected))
+	})
+
+	It("should honor the field coders and decode options of the Structomancer it's given", func() {
+		z := structomancer.New(&Album{}, "api")
+		z.SetFieldEncoder("name", func(x interface{}) (interface{}, error) { return strings.ToUpper(x.(string)), nil })
+		z.SetFieldDecoder("name", func(x interface{}) (interface{}, error) { return strings.ToLower(x.(string)), nil })
+		z.SetDecodeOptions(structomancer.DecodeOptions{DisallowUnknownKeys: true})
+
+		var buf bytes.Buffer
+		Expect(jsoncodec.NewEncoder(&buf, z).Encode(&Album{Name: "Tattoo You", Label: "x"})).To(Succeed())
+		Expect(buf.String()).To(HavePrefix(`{"name":"TATTOO YOU",`))
+		Expect(buf.String()).To(HaveSuffix("}\n"))
+
+		dec := jsoncodec.NewDecoder(strings.NewReader(`{"name": "SOME GIRLS", "label": "x"} {"label": "x", "bogus": 1}`), z)
+
+		var a Album
+		Expect(dec.Decode(&a)).To(Succeed())
+		Expect(a.Name).To(Equal("some girls"))
+
+		var unknownErr *structomancer.UnknownKeysError
+		Expect(errors.As(dec.Decode(&a), &unknownErr)).To(BeTrue())
+		Expect(unknownErr.Fields[0].Field).To(Equal("bogus"))
+
+		Expect(dec.Decode(&a)).To(Equal(io.EOF))
+	})
+
+	It("should report missing required fields and conversion errors with their paths", func() {
+		var a Album
+		err := jsoncodec.Unmarshal([]byte(`{"name": "x"}`), &a, "api")
+		var missingErr *structomancer.MissingFieldsError
+		Expect(errors.As(err, &missingErr)).To(BeTrue())
+		Expect(missingErr.Paths).To(Equal([]string{"label"}))
+
+		err = jsoncodec.Unmarshal([]byte(`{"label": "x", "tracks": [{"duration": 1.5}]}`), &a, "api")
+		var convErr *structomancer.ConversionError
+		Expect(errors.As(err, &convErr)).To(BeTrue())
+		Expect(convErr.Path).To(Equal("tracks[0].duration"))
+	})
+
+	It("should reject malformed input", func() {
+		var a Album
+		Expect(jsoncodec.Unmarshal([]byte(`{"label": "x"`), &a, "api")).NotTo(Succeed())
+		Expect(jsoncodec.Unmarshal([]byte(`[1, 2]`), &a, "api")).NotTo(Succeed())
+		Expect(jsoncodec.Unmarshal([]byte(`{"label": "x"} {}`), &a, "api")).NotTo(Succeed())
+	})
+
+	It("should leave unexported fields out", func() {
+		type Session struct {
+			User    string    `api:"user"`
+			token   string    `api:"token"`
+			expires time.Time `api:"expires"`
+		}
+
+		bs, err := jsoncodec.Marshal(Session{User: "keith", token: "hunter2", expires: time.Now()}, "api")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(bs)).To(Equal(`{"user":"keith"}`))
+
+		var s Session
+		Expect(jsoncodec.Unmarshal([]byte(`{"user": "keith", "token": "x"}`), &s, "api")).To(Succeed())
+		Expect(s).To(Equal(Session{User: "keith"}))
+	})
+
+	It("should return an error when asked to marshal something other than a struct", func() {
+		var nilErr *structomancer.NilStructError
+		_, err := jsoncodec.Marshal(nil, "api")
+		Expect(errors.As(err, &nilErr)).To(BeTrue())
+
+		var unsupportedErr *structomancer.UnsupportedTypeError
+		_, err = jsoncodec.Marshal(5, "api")
+		Expect(errors.As(err, &unsupportedErr)).To(BeTrue())
+	})
+
+	It("should return a NilStructError for nil destinations, and an error for non-pointers", func() {
+		var nilErr *structomancer.NilStructError
+		Expect(errors.As(jsoncodec.Unmarshal([]byte(`{}`), nil, "api"), &nilErr)).To(BeTrue())
+		Expect(errors.As(jsoncodec.Unmarshal([]byte(`{}`), (*Album)(nil), "api"), &nilErr)).To(BeTrue())
+
+		dec := jsoncodec.NewDecoder(strings.NewReader(`{}`), structomancer.New(&Album{}, "api"))
+		Expect(errors.As(dec.Decode(nil), &nilErr)).To(BeTrue())
+
+		err := jsoncodec.Unmarshal([]byte(`{}`), Album{}, "api")
+		Expect(err).To(MatchError(ContainSubstring("must be a non-nil pointer")))
+		err = dec.Decode(Album{})
+		Expect(err).To(MatchError(ContainSubstring("must be a non-nil pointer")))
+
+		var unsupportedErr *structomancer.UnsupportedTypeError
+		var n int
+		Expect(errors.As(jsoncodec.Unmarshal([]byte(`{}`), &n, "api"), &unsupportedErr)).To(BeTrue())
+		Expect(errors.As(dec.Decode(&n), &unsupportedErr)).To(BeTrue())
+		Expect(errors.As(dec.Decode(&Track{}), &unsupportedErr)).To(BeTrue())
+	})
+})
@@ -0,0 +1,99 @@
+package structomancer
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+type (
+	// Decides which keys match which fields when decoding (see DecodeOptions.KeyMatcher).  A key
+	// matches a field if normalizing the key gives the same result as normalizing the field's
+	// nickname.  Exact matches always take precedence, and keys matching more than one field match
+	// none of them.
+	KeyMatcher struct {
+		normalize func(string) string
+		names     sync.Map // *structSpec -> map[string]string (see normalizedNames)
+	}
+)
+
+var (
+	// Matches keys to field nicknames regardless of case, so "UserName" matches "username".
+	MatchCaseInsensitive = NewKeyMatcher(strings.ToLower)
+
+	// Matches keys to field nicknames regardless of case, underscores, hyphens, dots and spaces, so
+	// "user_name" and "User-Name" match "userName".
+	MatchIgnoringSeparators = NewKeyMatcher(func(s string) string {
+		return strings.Map(func(r rune) rune {
+			switch r {
+			case '_', '-', '.', ' ':
+				return -1
+			default:
+				return unicode.ToLower(r)
+			}
+		}, s)
+	})
+)
+
+// Returns a KeyMatcher that matches keys to field nicknames that `normalize` maps to the same string.
+// Field nicknames are normalized once per struct type and cached on the KeyMatcher, so create each
+// KeyMatcher once and reuse it.
+func NewKeyMatcher(normalize func(string) string) *KeyMatcher {
+	return &KeyMatcher{normalize: normalize}
+}
+
+// How closely a key matches a field: its nickname beats its aliases (in the order they're listed),
+// which beat anything matched by a KeyMatcher.
+const (
+	rankNickname   = 0
+	rankNormalized = math.MaxInt32
+)
+
+// Returns the nickname of the field in `s` that `key` matches (if there is one), and how closely it
+// matches.
+func (m *KeyMatcher) match(s *structSpec, key string) (string, int, bool) {
+	if s.Field(key) != nil {
+		return key, rankNickname, true
+	} else if fname, exists := s.aliases[key]; exists {
+		return fname, rankNickname + 1 + indexOf(s.Field(fname).aliases, key), true
+	} else if m == nil || m.normalize == nil {
+		return "", 0, false
+	}
+
+	fname := m.normalizedNames(s)[m.normalize(key)]
+	return fname, rankNormalized, fname != ""
+}
+
+// Returns a map from each normalized field nickname (and alias) in `s` to the nickname itself (or to
+// "" if names of more than one field normalize to it).  These are computed the first time they're
+// needed, then cached on the KeyMatcher (so they're dropped along with it).
+func (m *KeyMatcher) normalizedNames(s *structSpec) map[string]string {
+	if names, exists := m.names.Load(s); exists {
+		return names.(map[string]string)
+	}
+
+	names := make(map[string]string, len(s.fieldNames))
+	for _, fname := range s.fieldNames {
+		for _, name := range append([]string{fname}, s.Field(fname).aliases...) {
+			normalized := m.normalize(name)
+			if existing, exists := names[normalized]; exists && existing != fname {
+				names[normalized] = ""
+			} else if !exists {
+				names[normalized] = fname
+			}
+		}
+	}
+
+	actual, _ := m.names.LoadOrStore(s, names)
+	return actual.(map[string]string)
+}
+
+func indexOf(strs []string, s string) int {
+	for i := range strs {
+		if strs[i] == s {
+			return i
+		}
+	}
+	return -1
+}
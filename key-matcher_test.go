@@ -0,0 +1,92 @@
+package structomancer_test
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/brynbellomy/go-structomancer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Key matching", func() {
+	type Profile struct {
+		Bio string `xyzzy:"bio"`
+	}
+
+	type Account struct {
+		UserName string  `xyzzy:"userName, required"`
+		Email    string  `xyzzy:"email"`
+		Profile  Profile `xyzzy:"profile"`
+		Alpha    int     `xyzzy:"a_b"`
+		Beta     int     `xyzzy:"ab"`
+	}
+
+	decode := func(matcher *structomancer.KeyMatcher, fields map[string]interface{}) (*Account, error) {
+		z := structomancer.New(&Account{}, "xyzzy")
+		z.SetDecodeOptions(structomancer.DecodeOptions{KeyMatcher: matcher, DisallowUnknownKeys: true})
+		s, err := z.MapToStruct(fields)
+		if s == nil {
+			return nil, err
+		}
+		return s.(*Account), err
+	}
+
+	It("should match keys exactly by default", func() {
+		_, err := decode(nil, map[string]interface{}{"userName": "keith", "Email": "keith@example.com"})
+
+		var unknownErr *structomancer.UnknownKeysError
+		Expect(errors.As(err, &unknownErr)).To(BeTrue())
+	})
+
+	It("should match keys case-insensitively, including in nested structs", func() {
+		a, err := decode(structomancer.MatchCaseInsensitive, map[string]interface{}{
+			"USERNAME": "keith",
+			"Email":    "keith@example.com",
+			"Profile":  map[string]interface{}{"BIO": "guitar"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(a).To(Equal(&Account{UserName: "keith", Email: "keith@example.com", Profile: Profile{Bio: "guitar"}}))
+	})
+
+	It("should match keys using a custom normalizer", func() {
+		stripUnderscores := structomancer.NewKeyMatcher(func(s string) string {
+			return strings.ToLower(strings.ReplaceAll(s, "_", ""))
+		})
+
+		a, err := decode(stripUnderscores, map[string]interface{}{"user_name": "keith"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(a.UserName).To(Equal("keith"))
+	})
+
+	It("should prefer exact matches", func() {
+		a, err := decode(structomancer.MatchCaseInsensitive, map[string]interface{}{"userName": "keith", "USERNAME": "mick"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(a.UserName).To(Equal("keith"))
+	})
+
+	It("should break ties between keys matched by the KeyMatcher alphabetically", func() {
+		for i := 0; i < 20; i++ {
+			a, err := decode(structomancer.MatchIgnoringSeparators, map[string]interface{}{"user-name": "keith", "User_Name": "mick", "USER.NAME": "ronnie"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a.UserName).To(Equal("ronnie"))
+		}
+	})
+
+	It("should not match keys that normalize to more than one field", func() {
+		a, err := decode(structomancer.MatchIgnoringSeparators, map[string]interface{}{"user-name": "keith", "a_b": 1, "A-B": 2})
+
+		var unknownErr *structomancer.UnknownKeysError
+		Expect(errors.As(err, &unknownErr)).To(BeTrue())
+		Expect(unknownErr.Fields).To(HaveLen(1))
+		Expect(unknownErr.Fields[0].Field).To(Equal("A-B"))
+		Expect(a.UserName).To(Equal("keith"))
+		Expect(a.Alpha).To(Equal(1))
+	})
+
+	It("should count matched keys as present for required fields", func() {
+		_, err := decode(structomancer.MatchCaseInsensitive, map[string]interface{}{"USERNAME": "keith"})
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
@@ -0,0 +1,87 @@
+package structomancer
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+)
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// Returns `v` (or, if only its pointer type implements the interface, a pointer to a copy of `v`) as
+// an `iface`.  Pointers and interfaces are left to the caller to unwrap, so that nil values never
+// have their methods called.
+func implementation(v reflect.Value, iface reflect.Type) (interface{}, bool) {
+	if !v.IsValid() || v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface || !v.CanInterface() {
+		return nil, false
+	} else if v.Type().Implements(iface) {
+		return v.Interface(), true
+	} else if reflect.PtrTo(v.Type()).Implements(iface) {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		return ptr.Interface(), true
+	}
+	return nil, false
+}
+
+// Encodes values implementing encoding.TextMarshaler as strings, and values implementing
+// json.Marshaler as whatever native value their JSON decodes to.  Returns false if `v` implements
+// neither.
+func (e *encodeState) marshalNative(v reflect.Value) (reflect.Value, bool, error) {
+	if m, ok := implementation(v, textMarshalerType); ok {
+		text, err := m.(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return reflect.Value{}, true, &UserCoderError{Path: e.currentPath(), Err: err}
+		}
+		return reflect.ValueOf(string(text)), true, nil
+
+	} else if m, ok := implementation(v, jsonMarshalerType); ok {
+		bs, err := m.(json.Marshaler).MarshalJSON()
+		if err != nil {
+			return reflect.Value{}, true, &UserCoderError{Path: e.currentPath(), Err: err}
+		}
+
+		var x interface{}
+		if err := json.Unmarshal(bs, &x); err != nil {
+			return reflect.Value{}, true, &UserCoderError{Path: e.currentPath(), Err: err}
+		}
+		return reflect.ValueOf(x), true, nil
+	}
+	return reflect.Value{}, false, nil
+}
+
+// Decodes `nv` into a value of type `destType` using its encoding.TextUnmarshaler implementation (if
+// `nv` is a string) or its json.Unmarshaler implementation.  Returns false if `destType` implements
+// neither (or if `nv` is already a `destType`).
+func (d *decodeState) unmarshalNative(nv reflect.Value, destType reflect.Type) (reflect.Value, bool, error) {
+	if !nv.IsValid() || nv.Type() == destType || destType.Kind() == reflect.Ptr || destType.Kind() == reflect.Interface {
+		return reflect.Value{}, false, nil
+	}
+
+	ptr := reflect.PtrTo(destType)
+	if ptr.Implements(textUnmarshalerType) && nv.Kind() == reflect.String {
+		dest := reflect.New(destType)
+		if err := dest.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(nv.String())); err != nil {
+			return reflect.Value{}, true, d.fail(&UserCoderError{Path: d.currentPath(), Err: err})
+		}
+		return dest.Elem(), true, nil
+
+	} else if ptr.Implements(jsonUnmarshalerType) && nv.CanInterface() {
+		bs, err := json.Marshal(nv.Interface())
+		if err != nil {
+			return reflect.Value{}, true, d.fail(&ConversionError{Path: d.currentPath(), From: nv.Type(), To: destType})
+		}
+
+		dest := reflect.New(destType)
+		if err := dest.Interface().(json.Unmarshaler).UnmarshalJSON(bs); err != nil {
+			return reflect.Value{}, true, d.fail(&UserCoderError{Path: d.currentPath(), Err: err})
+		}
+		return dest.Elem(), true, nil
+	}
+	return reflect.Value{}, false, nil
+}
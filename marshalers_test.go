@@ -0,0 +1,98 @@
+package structomancer_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/brynbellomy/go-structomancer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type UserID struct {
+	N int
+}
+
+func (id *UserID) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("user-%d", id.N)), nil
+}
+
+func (id *UserID) UnmarshalText(text []byte) error {
+	if !strings.HasPrefix(string(text), "user-") {
+		return errors.New("bad user id")
+	}
+	_, err := fmt.Sscanf(string(text), "user-%d", &id.N)
+	return err
+}
+
+type Point struct {
+	X, Y int
+}
+
+func (p Point) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]int{p.X, p.Y})
+}
+
+func (p *Point) UnmarshalJSON(bs []byte) error {
+	var xy []int
+	if err := json.Unmarshal(bs, &xy); err != nil {
+		return err
+	} else if len(xy) != 2 {
+		return errors.New("a point needs two coordinates")
+	}
+	p.X, p.Y = xy[0], xy[1]
+	return nil
+}
+
+var _ = Describe("Marshalers", func() {
+	type Session struct {
+		Addr   net.IP   `xyzzy:"addr"`
+		User   UserID   `xyzzy:"user"`
+		Admin  *UserID  `xyzzy:"admin"`
+		Guests []UserID `xyzzy:"guests"`
+		Origin Point    `xyzzy:"origin"`
+	}
+
+	session := Session{
+		Addr:   net.ParseIP("10.0.0.1"),
+		User:   UserID{N: 7},
+		Admin:  &UserID{N: 1},
+		Guests: []UserID{{N: 2}, {N: 3}},
+		Origin: Point{X: 4, Y: 5},
+	}
+
+	native := map[string]interface{}{
+		"addr":   "10.0.0.1",
+		"user":   "user-7",
+		"admin":  "user-1",
+		"guests": []interface{}{"user-2", "user-3"},
+		"origin": []interface{}{float64(4), float64(5)},
+	}
+
+	It("should encode TextMarshalers as strings and json.Marshalers as their JSON's native value", func() {
+		z := structomancer.New(&Session{}, "xyzzy")
+		m, err := z.StructToNativeMap(&session)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m).To(Equal(native))
+	})
+
+	It("should decode TextUnmarshalers from strings and json.Unmarshalers from native values", func() {
+		z := structomancer.New(&Session{}, "xyzzy")
+		s, err := z.MapToStruct(native)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s).To(Equal(&session))
+	})
+
+	It("should report unmarshaling errors along with their path", func() {
+		z := structomancer.New(&Session{}, "xyzzy")
+		_, err := z.MapToStruct(map[string]interface{}{"guests": []interface{}{"user-2", "nobody"}})
+
+		var coderErr *structomancer.UserCoderError
+		Expect(errors.As(err, &coderErr)).To(BeTrue())
+		Expect(coderErr.Path).To(Equal("guests[1]"))
+	})
+})
@@ -0,0 +1,114 @@
+package structomancer
+
+import (
+	"strings"
+	"unicode"
+)
+
+type (
+	// Derives the nicknames of fields whose tags don't give them one (see WithNamingStrategy).
+	NamingStrategy struct {
+		name   string
+		rename func(goName string) string
+	}
+
+	// Configures a Structomancer created by New, NewWithType or For.
+	Option func(z *Structomancer)
+)
+
+var (
+	// "UserID" -> "user_id"
+	SnakeCase = NewNamingStrategy("snake_case", func(goName string) string {
+		return joinWords(splitWords(goName), "_", strings.ToLower)
+	})
+
+	// "UserID" -> "userId"
+	CamelCase = NewNamingStrategy("camelCase", func(goName string) string {
+		words := splitWords(goName)
+		for i, word := range words {
+			if i == 0 {
+				words[i] = strings.ToLower(word)
+			} else {
+				words[i] = strings.ToUpper(word[:1]) + strings.ToLower(word[1:])
+			}
+		}
+		return strings.Join(words, "")
+	})
+
+	// "UserID" -> "user-id"
+	KebabCase = NewNamingStrategy("kebab-case", func(goName string) string {
+		return joinWords(splitWords(goName), "-", strings.ToLower)
+	})
+
+	// "UserID" -> "USER_ID"
+	ScreamingSnakeCase = NewNamingStrategy("SCREAMING_SNAKE_CASE", func(goName string) string {
+		return joinWords(splitWords(goName), "_", strings.ToUpper)
+	})
+)
+
+// Returns a NamingStrategy that derives nicknames from Go field names using `rename`.  Struct specs
+// are cached per strategy, so create each NamingStrategy once and reuse it.
+func NewNamingStrategy(name string, rename func(goName string) string) *NamingStrategy {
+	return &NamingStrategy{name: name, rename: rename}
+}
+
+// Returns the strategy's name.
+func (s *NamingStrategy) Name() string {
+	return s.name
+}
+
+// Returns the nickname this strategy gives a field with the Go name `goName`.  A nil strategy uses the
+// Go name itself.
+func (s *NamingStrategy) Rename(goName string) string {
+	if s == nil || s.rename == nil {
+		return goName
+	}
+	return s.rename(goName)
+}
+
+// Sets the NamingStrategy used to derive the nicknames of fields whose tags don't give them one
+// (including the fields of nested structs).  By default, their Go names are used.
+func WithNamingStrategy(strategy *NamingStrategy) Option {
+	return func(z *Structomancer) {
+		z.naming = strategy
+	}
+}
+
+// Splits a Go identifier into words, keeping acronyms together: "HTTPServerID" becomes
+// ["HTTP", "Server", "ID"].  Digits stay with the word before them, and underscores separate words.
+func splitWords(s string) []string {
+	runes := []rune(s)
+
+	var words []string
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '_' {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+
+		if i > start && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+func joinWords(words []string, sep string, transform func(string) string) string {
+	for i := range words {
+		words[i] = transform(words[i])
+	}
+	return strings.Join(words, sep)
+}
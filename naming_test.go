@@ -0,0 +1,73 @@
+package structomancer_test
+
+import (
+	"strings"
+
+	"github.com/brynbellomy/go-structomancer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Naming strategies", func() {
+	type Owner struct {
+		FullName string
+	}
+
+	type Server struct {
+		UserID      int
+		HTTPServer  string
+		Base64Value string
+		ID          int
+		Tagged      string  `xyzzy:"explicit"`
+		Owner       Owner   `xyzzy:",omitempty"`
+		Backup      *Server `xyzzy:"-"`
+	}
+
+	server := Server{UserID: 1, HTTPServer: "nginx", Base64Value: "aGk=", ID: 2, Tagged: "t", Owner: Owner{FullName: "keith"}}
+
+	It("should derive the nicknames of untagged fields, keeping acronyms together", func() {
+		expected := map[*structomancer.NamingStrategy][]string{
+			structomancer.SnakeCase:                                   {"user_id", "http_server", "base64_value", "id", "explicit", "owner"},
+			structomancer.CamelCase:                                   {"userId", "httpServer", "base64Value", "id", "explicit", "owner"},
+			structomancer.KebabCase:                                   {"user-id", "http-server", "base64-value", "id", "explicit", "owner"},
+			structomancer.ScreamingSnakeCase:                          {"USER_ID", "HTTP_SERVER", "BASE64_VALUE", "ID", "explicit", "OWNER"},
+			structomancer.NewNamingStrategy("lower", strings.ToLower): {"userid", "httpserver", "base64value", "id", "explicit", "owner"},
+		}
+
+		for strategy, names := range expected {
+			z := structomancer.New(&Server{}, "xyzzy", structomancer.WithNamingStrategy(strategy))
+			Expect(z.FieldNames()).To(Equal(names), strategy.Name())
+		}
+	})
+
+	It("should apply the strategy to nested structs when encoding and decoding", func() {
+		z := structomancer.New(&Server{}, "xyzzy", structomancer.WithNamingStrategy(structomancer.SnakeCase))
+
+		m, err := z.StructToNativeMap(&server)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m).To(Equal(map[string]interface{}{
+			"user_id":      1,
+			"http_server":  "nginx",
+			"base64_value": "aGk=",
+			"id":           2,
+			"explicit":     "t",
+			"owner":        map[string]interface{}{"full_name": "keith"},
+		}))
+
+		s, err := z.MapToStruct(m)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s).To(Equal(&server))
+
+		Expect(z.GetFieldValue(&server, "owner.full_name")).To(Equal("keith"))
+	})
+
+	It("should cache specs separately for each strategy", func() {
+		snake := structomancer.New(&Server{}, "xyzzy", structomancer.WithNamingStrategy(structomancer.SnakeCase))
+		plain := structomancer.New(&Server{}, "xyzzy")
+
+		Expect(snake.IsKnownField("user_id")).To(BeTrue())
+		Expect(plain.IsKnownField("user_id")).To(BeFalse())
+		Expect(plain.IsKnownField("UserID")).To(BeTrue())
+	})
+})
@@ -0,0 +1,194 @@
+package structomancer
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+var (
+	jsonNumberType = reflect.TypeOf(json.Number(""))
+	bigIntType     = reflect.TypeOf(big.Int{})
+	bigFloatType   = reflect.TypeOf(big.Float{})
+	bigRatType     = reflect.TypeOf(big.Rat{})
+)
+
+func isBigType(t reflect.Type) bool {
+	return t == bigIntType || t == bigFloatType || t == bigRatType
+}
+
+// Converts json.Number and math/big sources bound for a numeric (or time.Time) destination into
+// plain int64, uint64 or float64 values, so that they go through the same checked conversions as any
+// other number.  Any other value is returned unchanged.  Returns false if `nv` couldn't be converted.
+func (d *decodeState) normalizeNumber(nv reflect.Value, destType reflect.Type) (reflect.Value, bool, error) {
+	if !nv.IsValid() || !(isNumberKind(destType.Kind()) || isComplexKind(destType.Kind()) || destType == timeType) {
+		return nv, true, nil
+	}
+
+	if nv.Type() == jsonNumberType {
+		n, reason := parseNumber(nv.String())
+		if reason != "" {
+			return reflect.Value{}, false, d.fail(&ConversionError{Path: d.currentPath(), From: nv.Type(), To: destType, Reason: reason})
+		}
+		return n, true, nil
+	}
+
+	if nv.Kind() == reflect.Ptr && !nv.IsNil() && isBigType(nv.Type().Elem()) {
+		nv = nv.Elem()
+	}
+	if !isBigType(nv.Type()) {
+		return nv, true, nil
+	}
+
+	n, exact := bigToNative(nv)
+	if !exact && !d.opts.AllowLossyNumbers {
+		return reflect.Value{}, false, d.fail(&ConversionError{Path: d.currentPath(), From: nv.Type(), To: destType, Reason: reasonPrecision})
+	}
+	return n, true, nil
+}
+
+// Parses a JSON number into an int64 if it's an integer that fits, a uint64 if it's too big for an
+// int64, or otherwise a float64.  Returns the reason if it can't be parsed.
+func parseNumber(s string) (reflect.Value, string) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return reflect.ValueOf(n), ""
+	} else if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return reflect.ValueOf(n), ""
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if numErr, isNumErr := err.(*strconv.NumError); isNumErr && numErr.Err == strconv.ErrRange {
+		return reflect.Value{}, reasonOverflow
+	} else if err != nil {
+		return reflect.Value{}, "not a number"
+	}
+	return reflect.ValueOf(f), ""
+}
+
+// Converts a big.Int, big.Float or big.Rat into an int64, a uint64 or (failing those) a float64.
+// Returns false if the result isn't exactly equal to `v`.
+func bigToNative(v reflect.Value) (reflect.Value, bool) {
+	switch x := bigPointer(v).(type) {
+	case *big.Int:
+		if x.IsInt64() {
+			return reflect.ValueOf(x.Int64()), true
+		} else if x.IsUint64() {
+			return reflect.ValueOf(x.Uint64()), true
+		}
+		f, accuracy := new(big.Float).SetInt(x).Float64()
+		return reflect.ValueOf(f), accuracy == big.Exact
+
+	case *big.Float:
+		if x.IsInt() {
+			if n, accuracy := x.Int64(); accuracy == big.Exact {
+				return reflect.ValueOf(n), true
+			} else if n, accuracy := x.Uint64(); accuracy == big.Exact {
+				return reflect.ValueOf(n), true
+			}
+		}
+		f, accuracy := x.Float64()
+		return reflect.ValueOf(f), accuracy == big.Exact
+
+	case *big.Rat:
+		if x.IsInt() {
+			return bigToNative(reflect.ValueOf(x.Num()).Elem())
+		}
+		f, exact := x.Float64()
+		return reflect.ValueOf(f), exact
+	}
+	panic("structomancer: bigToNative called on a " + v.Type().String())
+}
+
+// Decodes numbers (including json.Numbers, numeric strings and other math/big values) into a
+// big.Int, big.Float or big.Rat.  Returns false if `destType` is none of those.
+func (d *decodeState) decodeBig(nv reflect.Value, destType reflect.Type) (reflect.Value, bool, error) {
+	if !isBigType(destType) || !nv.IsValid() {
+		return reflect.Value{}, false, nil
+	}
+
+	if nv.Kind() == reflect.Ptr && !nv.IsNil() && isBigType(nv.Type().Elem()) {
+		nv = nv.Elem()
+	}
+
+	// big values share their internal storage when copied, so the caller's value is never reused as is
+	if nv.Type() == destType {
+		return copyBig(nv), true, nil
+	}
+
+	// convert everything to a big.Rat first, since any finite number can be represented exactly by one
+	var r *big.Rat
+	reason := ""
+
+	switch {
+	case nv.Kind() == reflect.String:
+		var ok bool
+		if r, ok = new(big.Rat).SetString(nv.String()); !ok {
+			reason = "not a number"
+		}
+	case isIntKind(nv.Kind()):
+		r = new(big.Rat).SetInt64(nv.Int())
+	case isUintKind(nv.Kind()):
+		r = new(big.Rat).SetUint64(nv.Uint())
+	case isFloatKind(nv.Kind()):
+		if r = new(big.Rat).SetFloat64(nv.Float()); r == nil {
+			reason = "not a finite number"
+		}
+	case nv.Type() == bigIntType:
+		r = new(big.Rat).SetInt(bigPointer(nv).(*big.Int))
+	case nv.Type() == bigFloatType:
+		f := bigPointer(nv).(*big.Float)
+		if f.IsInf() {
+			reason = "not a finite number"
+		} else {
+			r, _ = f.Rat(nil)
+		}
+	case nv.Type() == bigRatType:
+		r = bigPointer(nv).(*big.Rat)
+	default:
+		return reflect.Value{}, true, d.fail(&ConversionError{Path: d.currentPath(), From: nv.Type(), To: destType})
+	}
+
+	if reason == "" && destType == bigIntType && !r.IsInt() && !d.opts.AllowLossyNumbers {
+		reason = reasonTruncated
+	}
+	if reason != "" {
+		return reflect.Value{}, true, d.fail(&ConversionError{Path: d.currentPath(), From: nv.Type(), To: destType, Reason: reason})
+	}
+
+	switch destType {
+	case bigIntType:
+		return reflect.ValueOf(new(big.Int).Quo(r.Num(), r.Denom())).Elem(), true, nil
+	case bigFloatType:
+		return reflect.ValueOf(new(big.Float).SetRat(r)).Elem(), true, nil
+	default:
+		return reflect.ValueOf(new(big.Rat).Set(r)).Elem(), true, nil
+	}
+}
+
+// Returns a pointer to the big.Int, big.Float or big.Rat in `v` (copying it if it isn't addressable),
+// since their methods all have pointer receivers.
+func bigPointer(v reflect.Value) interface{} {
+	if v.CanAddr() {
+		return v.Addr().Interface()
+	}
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	return ptr.Interface()
+}
+
+// Returns a reflect.Value containing a deep copy of the math/big value contained by `v`.
+func copyBig(v reflect.Value) reflect.Value {
+	switch x := bigPointer(v).(type) {
+	case *big.Int:
+		return reflect.ValueOf(new(big.Int).Set(x)).Elem()
+	case *big.Float:
+		return reflect.ValueOf(new(big.Float).Copy(x)).Elem()
+	default:
+		return reflect.ValueOf(new(big.Rat).Set(x.(*big.Rat))).Elem()
+	}
+}
+
+func isComplexKind(k reflect.Kind) bool {
+	return k == reflect.Complex64 || k == reflect.Complex128
+}
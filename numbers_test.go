@@ -0,0 +1,148 @@
+package structomancer_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/brynbellomy/go-structomancer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("json.Number and math/big", func() {
+	type Ledger struct {
+		Count    int           `xyzzy:"count"`
+		Small    int8          `xyzzy:"small"`
+		Ratio    float32       `xyzzy:"ratio"`
+		Huge     uint64        `xyzzy:"huge"`
+		Timeout  time.Duration `xyzzy:"timeout"`
+		Stamp    time.Time     `xyzzy:"stamp, format=unix"`
+		Label    string        `xyzzy:"label"`
+		Balance  *big.Int      `xyzzy:"balance"`
+		Rate     *big.Float    `xyzzy:"rate"`
+		Share    *big.Rat      `xyzzy:"share"`
+		Supply   big.Int       `xyzzy:"supply"`
+		FromBig  int64         `xyzzy:"fromBig"`
+		FromRat  float64       `xyzzy:"fromRat"`
+		Overflow int64         `xyzzy:"overflow"`
+	}
+
+	decodeJSON := func(s string) map[string]interface{} {
+		dec := json.NewDecoder(strings.NewReader(s))
+		dec.UseNumber()
+
+		var m map[string]interface{}
+		Expect(dec.Decode(&m)).To(Succeed())
+		return m
+	}
+
+	It("should decode json.Numbers into every numeric kind", func() {
+		z := structomancer.New(&Ledger{}, "xyzzy")
+		s, err := z.MapToStruct(decodeJSON(`{
+			"count": 42, "small": -8, "ratio": 0.25, "huge": 18446744073709551615,
+			"timeout": 1000000000, "stamp": 1614834367, "label": 123
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		ledger := s.(*Ledger)
+		Expect(ledger.Count).To(Equal(42))
+		Expect(ledger.Small).To(Equal(int8(-8)))
+		Expect(ledger.Ratio).To(Equal(float32(0.25)))
+		Expect(ledger.Huge).To(Equal(uint64(18446744073709551615)))
+		Expect(ledger.Timeout).To(Equal(time.Second))
+		Expect(ledger.Stamp.Equal(time.Unix(1614834367, 0))).To(BeTrue())
+		Expect(ledger.Label).To(Equal("123"))
+	})
+
+	It("should apply the usual checks to json.Numbers", func() {
+		z := structomancer.New(&Ledger{}, "xyzzy")
+
+		var convErr *structomancer.ConversionError
+		_, err := z.MapToStruct(decodeJSON(`{"small": 300}`))
+		Expect(errors.As(err, &convErr)).To(BeTrue())
+		Expect(convErr.Reason).To(Equal("overflow"))
+
+		_, err = z.MapToStruct(decodeJSON(`{"count": 1.5}`))
+		Expect(errors.As(err, &convErr)).To(BeTrue())
+		Expect(convErr.Reason).To(Equal("fractional truncation"))
+	})
+
+	It("should decode math/big fields without losing precision", func() {
+		z := structomancer.New(&Ledger{}, "xyzzy")
+		s, err := z.MapToStruct(decodeJSON(`{
+			"balance": 123456789012345678901234567890,
+			"rate": 0.5,
+			"share": "1/3",
+			"supply": 7
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		ledger := s.(*Ledger)
+		expected, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+		Expect(ledger.Balance.Cmp(expected)).To(Equal(0))
+		Expect(ledger.Rate.Cmp(big.NewFloat(0.5))).To(Equal(0))
+		Expect(ledger.Share.Cmp(big.NewRat(1, 3))).To(Equal(0))
+		Expect(ledger.Supply.Int64()).To(Equal(int64(7)))
+
+		_, err = z.MapToStruct(map[string]interface{}{"balance": 1.5})
+		var convErr *structomancer.ConversionError
+		Expect(errors.As(err, &convErr)).To(BeTrue())
+		Expect(convErr.Reason).To(Equal("fractional truncation"))
+	})
+
+	It("should decode math/big values into native numeric fields", func() {
+		z := structomancer.New(&Ledger{}, "xyzzy")
+		huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+		s, err := z.MapToStruct(map[string]interface{}{
+			"fromBig": big.NewInt(-5),
+			"fromRat": big.NewRat(1, 4),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.(*Ledger).FromBig).To(Equal(int64(-5)))
+		Expect(s.(*Ledger).FromRat).To(Equal(0.25))
+
+		_, err = z.MapToStruct(map[string]interface{}{"overflow": huge})
+		var convErr *structomancer.ConversionError
+		Expect(errors.As(err, &convErr)).To(BeTrue())
+		Expect(convErr.Path).To(Equal("overflow"))
+	})
+
+	It("should copy math/big values rather than sharing them with the input", func() {
+		z := structomancer.New(&Ledger{}, "xyzzy")
+		balance := big.NewInt(12)
+		rate := big.NewFloat(0.5)
+		share := big.NewRat(2, 3)
+		supply := *big.NewInt(7)
+
+		s, err := z.MapToStruct(map[string]interface{}{"balance": balance, "rate": rate, "share": share, "supply": supply})
+		Expect(err).NotTo(HaveOccurred())
+		balance.SetInt64(100)
+		rate.SetFloat64(100)
+		share.SetInt64(100)
+		supply.SetInt64(100)
+
+		ledger := s.(*Ledger)
+		Expect(ledger.Balance.Int64()).To(Equal(int64(12)))
+		Expect(ledger.Rate.Cmp(big.NewFloat(0.5))).To(Equal(0))
+		Expect(ledger.Share.Cmp(big.NewRat(2, 3))).To(Equal(0))
+		Expect(ledger.Supply.Int64()).To(Equal(int64(7)))
+	})
+
+	It("should round-trip math/big fields through StructToNativeMap", func() {
+		z := structomancer.New(&Ledger{}, "xyzzy")
+		m, err := z.StructToNativeMap(&Ledger{Balance: big.NewInt(12), Share: big.NewRat(2, 3)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m["balance"]).To(Equal("12"))
+		Expect(m["share"]).To(Equal("2/3"))
+
+		s, err := z.MapToStruct(m)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.(*Ledger).Balance.Int64()).To(Equal(int64(12)))
+		Expect(s.(*Ledger).Share.Cmp(big.NewRat(2, 3))).To(Equal(0))
+	})
+})
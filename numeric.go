@@ -0,0 +1,97 @@
+package structomancer
+
+import (
+	"math"
+	"reflect"
+)
+
+const (
+	reasonOverflow  = "overflow"
+	reasonSignLoss  = "sign loss"
+	reasonTruncated = "fractional truncation"
+	reasonPrecision = "precision loss"
+)
+
+// Returns the reason converting the numeric value `v` to `destType` would lose information, or ""
+// if it wouldn't (or if either isn't numeric).
+func lossyNumericConversion(v reflect.Value, destType reflect.Type) string {
+	dest := reflect.Zero(destType)
+	destKind := destType.Kind()
+
+	switch {
+	case isIntKind(v.Kind()):
+		n := v.Int()
+		switch {
+		case isIntKind(destKind):
+			if dest.OverflowInt(n) {
+				return reasonOverflow
+			}
+		case isUintKind(destKind):
+			if n < 0 {
+				return reasonSignLoss
+			} else if dest.OverflowUint(uint64(n)) {
+				return reasonOverflow
+			}
+		case isFloatKind(destKind):
+			if f := roundToBits(float64(n), destType.Bits()); f >= math.MaxInt64 || int64(f) != n {
+				return reasonPrecision
+			}
+		}
+
+	case isUintKind(v.Kind()):
+		n := v.Uint()
+		switch {
+		case isIntKind(destKind):
+			if n > math.MaxInt64 || dest.OverflowInt(int64(n)) {
+				return reasonOverflow
+			}
+		case isUintKind(destKind):
+			if dest.OverflowUint(n) {
+				return reasonOverflow
+			}
+		case isFloatKind(destKind):
+			if f := roundToBits(float64(n), destType.Bits()); f >= math.MaxUint64 || uint64(f) != n {
+				return reasonPrecision
+			}
+		}
+
+	case isFloatKind(v.Kind()):
+		f := v.Float()
+		switch {
+		case isIntKind(destKind), isUintKind(destKind):
+			if math.IsNaN(f) || math.IsInf(f, 0) {
+				return reasonOverflow
+			} else if f != math.Trunc(f) {
+				return reasonTruncated
+			} else if isUintKind(destKind) && f < 0 {
+				return reasonSignLoss
+			} else if isUintKind(destKind) && (f >= math.MaxUint64 || dest.OverflowUint(uint64(f))) {
+				return reasonOverflow
+			} else if isIntKind(destKind) && (f < math.MinInt64 || f >= math.MaxInt64 || dest.OverflowInt(int64(f))) {
+				return reasonOverflow
+			}
+		case destKind == reflect.Float32, destKind == reflect.Complex64:
+			if !math.IsInf(f, 0) && math.Abs(f) > math.MaxFloat32 {
+				return reasonOverflow
+			}
+		}
+
+	case v.Kind() == reflect.Complex128 && destKind == reflect.Complex64:
+		if c := v.Complex(); dest.OverflowComplex(c) {
+			return reasonOverflow
+		}
+	}
+	return ""
+}
+
+// Rounds `f` to the nearest float of the given bit size.
+func roundToBits(f float64, bits int) float64 {
+	if bits == 32 {
+		return float64(float32(f))
+	}
+	return f
+}
+
+func isFloatKind(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
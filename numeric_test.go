@@ -0,0 +1,89 @@
+package structomancer_test
+
+import (
+	"errors"
+	"math"
+
+	"github.com/brynbellomy/go-structomancer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Numeric conversions", func() {
+	type Numbers struct {
+		Small   int8             `xyzzy:"small"`
+		Count   uint             `xyzzy:"count"`
+		Whole   int              `xyzzy:"whole"`
+		Big     int64            `xyzzy:"big"`
+		Single  float32          `xyzzy:"single"`
+		Double  float64          `xyzzy:"double"`
+		Complex complex64        `xyzzy:"complex"`
+		Bytes   []uint8          `xyzzy:"bytes"`
+		Scores  map[string]int16 `xyzzy:"scores"`
+	}
+
+	reasonFor := func(fields map[string]interface{}) string {
+		z := structomancer.New(&Numbers{}, "xyzzy")
+		_, err := z.MapToStruct(fields)
+
+		var convErr *structomancer.ConversionError
+		if !errors.As(err, &convErr) {
+			return ""
+		}
+		return convErr.Path + ": " + convErr.Reason
+	}
+
+	It("should convert numbers that fit in their destination", func() {
+		z := structomancer.New(&Numbers{}, "xyzzy")
+		s, err := z.MapToStruct(map[string]interface{}{
+			"small":   int64(-128),
+			"count":   3.0,
+			"whole":   uint64(42),
+			"big":     float64(1 << 53),
+			"single":  0.5,
+			"double":  int64(1 << 53),
+			"complex": complex128(1 + 2i),
+			"bytes":   []interface{}{255, 0},
+			"scores":  map[string]interface{}{"a": -7},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s).To(Equal(&Numbers{
+			Small:   -128,
+			Count:   3,
+			Whole:   42,
+			Big:     1 << 53,
+			Single:  0.5,
+			Double:  1 << 53,
+			Complex: 1 + 2i,
+			Bytes:   []uint8{255, 0},
+			Scores:  map[string]int16{"a": -7},
+		}))
+	})
+
+	It("should refuse conversions that overflow", func() {
+		Expect(reasonFor(map[string]interface{}{"small": int64(300)})).To(Equal("small: overflow"))
+		Expect(reasonFor(map[string]interface{}{"whole": uint64(math.MaxUint64)})).To(Equal("whole: overflow"))
+		Expect(reasonFor(map[string]interface{}{"big": 1e19})).To(Equal("big: overflow"))
+		Expect(reasonFor(map[string]interface{}{"single": 1e39})).To(Equal("single: overflow"))
+		Expect(reasonFor(map[string]interface{}{"complex": complex(1e39, 0)})).To(Equal("complex: overflow"))
+		Expect(reasonFor(map[string]interface{}{"bytes": []interface{}{1, 256}})).To(Equal("bytes[1]: overflow"))
+		Expect(reasonFor(map[string]interface{}{"scores": map[string]interface{}{"a": 40000}})).To(Equal("scores[a]: overflow"))
+	})
+
+	It("should refuse conversions that lose the sign, the fraction, or precision", func() {
+		Expect(reasonFor(map[string]interface{}{"count": -1})).To(Equal("count: sign loss"))
+		Expect(reasonFor(map[string]interface{}{"whole": 1.9})).To(Equal("whole: fractional truncation"))
+		Expect(reasonFor(map[string]interface{}{"double": int64(1<<53 + 1)})).To(Equal("double: precision loss"))
+	})
+
+	It("should allow lossy conversions when the AllowLossyNumbers option is set", func() {
+		z := structomancer.New(&Numbers{}, "xyzzy")
+		z.SetDecodeOptions(structomancer.DecodeOptions{AllowLossyNumbers: true})
+
+		s, err := z.MapToStruct(map[string]interface{}{"small": int64(300), "whole": 1.9})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.(*Numbers).Small).To(Equal(int8(44)))
+		Expect(s.(*Numbers).Whole).To(Equal(1))
+	})
+})
@@ -0,0 +1,101 @@
+package structomancer
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Parses the string `s` into a value of type `t`.  Slices and arrays are parsed from a list of
+// elements separated by "|" (since struct tag flags are already separated by commas), and
+// time.Durations are parsed with time.ParseDuration.
+func parseString(s string, t reflect.Type) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+
+	if t == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return reflect.Value{}, errors.Errorf("cannot parse %q as %v", s, t)
+		}
+		v.SetInt(int64(d))
+		return v, nil
+	}
+
+	var err error
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(s)
+
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(s)
+		v.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		n, err = strconv.ParseInt(s, 10, t.Bits())
+		v.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		n, err = strconv.ParseUint(s, 10, t.Bits())
+		v.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(s, t.Bits())
+		v.SetFloat(f)
+
+	case reflect.Complex64, reflect.Complex128:
+		var c complex128
+		c, err = strconv.ParseComplex(s, t.Bits())
+		v.SetComplex(c)
+
+	case reflect.Ptr:
+		inner, err := parseString(s, t.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.Set(reflect.New(t.Elem()))
+		v.Elem().Set(inner)
+
+	case reflect.Slice, reflect.Array:
+		var parts []string
+		if s != "" {
+			parts = strings.Split(s, "|")
+		}
+
+		if t.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(t, len(parts), len(parts)))
+		} else if len(parts) > t.Len() {
+			return reflect.Value{}, errors.Errorf("cannot parse %q as %v: too many elements", s, t)
+		}
+
+		for i, part := range parts {
+			elem, err := parseString(part, t.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v.Index(i).Set(elem)
+		}
+
+	case reflect.Interface:
+		if t.NumMethod() > 0 {
+			return reflect.Value{}, errors.Errorf("cannot parse %q as %v", s, t)
+		}
+		v.Set(reflect.ValueOf(s))
+
+	default:
+		return reflect.Value{}, errors.Errorf("cannot parse %q as %v", s, t)
+	}
+
+	if err != nil {
+		return reflect.Value{}, errors.Errorf("cannot parse %q as %v", s, t)
+	}
+	return v, nil
+}
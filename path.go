@@ -0,0 +1,347 @@
+package structomancer
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// A single component of a path like `inner.bar[2]`: either a field nickname or an index/map key.
+	pathSegment struct {
+		name  string
+		isKey bool
+	}
+)
+
+// Splits a path like `structSlice[0].bar` or `mapOfStructs[key].foo` into its segments.  Paths must
+// begin with a field nickname.
+func parsePath(path string) ([]pathSegment, error) {
+	var segs []pathSegment
+
+	rest := path
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, errors.Errorf("structomancer: bad path '%v': unterminated '['", path)
+			}
+			segs = append(segs, pathSegment{name: rest[1:end], isKey: true})
+			rest = rest[end+1:]
+
+			if len(rest) > 0 && rest[0] != '.' && rest[0] != '[' {
+				return nil, errors.Errorf("structomancer: bad path '%v': unexpected '%v' after ']'", path, rest[:1])
+			}
+
+		case '.':
+			if len(segs) == 0 {
+				return nil, errors.Errorf("structomancer: bad path '%v': empty field name", path)
+			}
+			rest = rest[1:]
+			fallthrough
+
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, errors.Errorf("structomancer: bad path '%v': empty field name", path)
+			}
+			segs = append(segs, pathSegment{name: rest[:end]})
+			rest = rest[end:]
+		}
+	}
+
+	if len(segs) == 0 || segs[0].isKey {
+		return nil, errors.Errorf("structomancer: bad path '%v': paths must begin with a field name", path)
+	}
+	return segs, nil
+}
+
+// Reassembles (a prefix of) a parsed path, for use in error messages.
+func formatPath(segs []pathSegment) string {
+	var sb strings.Builder
+	for i, seg := range segs {
+		if seg.isKey {
+			sb.WriteString("[" + seg.name + "]")
+		} else {
+			if i > 0 {
+				sb.WriteByte('.')
+			}
+			sb.WriteString(seg.name)
+		}
+	}
+	return sb.String()
+}
+
+// Returns the path of the field named `name` in the struct at `structPath`.
+func joinPath(structPath string, name string) string {
+	if structPath == "" {
+		return name
+	}
+	return structPath + "." + name
+}
+
+func pathErrorf(op string, segs []pathSegment, i int, format string, args ...interface{}) error {
+	return &PathError{Path: formatPath(segs[:i+1]), Op: op, Reason: fmt.Sprintf(format, args...)}
+}
+
+// Returns true if `name` is a path (see parsePath) rather than the nickname or alias of a single
+// field.  Nicknames that happen to contain "." or "[" are still treated as nicknames.
+func (z *Structomancer) isPath(name string) bool {
+	if z.Field(name) != nil {
+		return false
+	} else if _, isAlias := z.aliases[name]; isAlias {
+		return false
+	}
+	return strings.ContainsAny(name, ".[")
+}
+
+// Returns the tag name used for the value found at `path` (which may also be a field's nickname): the
+// "@tag" subtag of the last field along the way, if it has one.
+func (z *Structomancer) subtagForPath(path string) string {
+	if field := z.Field(path); field != nil {
+		return z.subtagFor(field)
+	}
+
+	segs, err := parsePath(path)
+	if err != nil {
+		return z.tagName
+	}
+
+	t, tagName := z.Type(), z.tagName
+	for _, seg := range segs {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if seg.isKey {
+			if t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+				t = t.Elem()
+			}
+			continue
+		} else if t.Kind() != reflect.Struct {
+			break
+		}
+
+		field := structSpecForType(t, tagName, z.naming).Field(seg.name)
+		if field == nil {
+			break
+		}
+		t, tagName = field.Type(), subtagOf(field, tagName)
+	}
+	return tagName
+}
+
+// Returns the value found at `path` in the struct contained by `v`.  Nested structs' nicknames are
+// resolved using each field's "@tag" subtag.  See GetFieldValue.
+func (z *Structomancer) getPathValueV(v reflect.Value, path string) (reflect.Value, error) {
+	segs, err := parsePath(path)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return z.walkPath("GetFieldValue", v, segs)
+}
+
+// Returns a pointer to the value found at `path` in the struct contained by `v`.  Map elements aren't
+// addressable, so paths that end inside of a map value return an error.  See PointerToField.
+func (z *Structomancer) pointerToPathV(v reflect.Value, path string) (reflect.Value, error) {
+	segs, err := parsePath(path)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	fv, err := z.walkPath("PointerToField", v, segs)
+	if err != nil {
+		return reflect.Value{}, err
+	} else if !fv.CanAddr() {
+		return reflect.Value{}, pathErrorf("PointerToField", segs, len(segs)-1, "value is not addressable")
+	}
+	return fv.Addr(), nil
+}
+
+// Sets the value found at `path` in the struct pointed to by `sv` to `value`, converting it with
+// FromNativeValue.  Nil pointers and maps along the way are allocated, and slices are grown as needed
+// to fit the given indices.  See SetFieldValue.
+func (z *Structomancer) setPathValueV(sv reflect.Value, path string, value reflect.Value) error {
+	segs, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+
+	if !sv.IsValid() || (sv.Kind() == reflect.Ptr && sv.IsNil()) {
+		return &NilStructError{Type: z.Type()}
+	} else if !IsStructPtrValue(sv) {
+		return &UnsupportedTypeError{Op: "SetFieldValue", Type: sv.Type()}
+	}
+	return z.setPath(sv.Elem(), z.tagName, "", segs, 0, value)
+}
+
+func (z *Structomancer) walkPath(op string, v reflect.Value, segs []pathSegment) (reflect.Value, error) {
+	if !v.IsValid() {
+		return reflect.Value{}, &NilStructError{Type: z.Type()}
+	}
+
+	tagName := z.tagName
+
+	for i, seg := range segs {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}, &NilStructError{Path: formatPath(segs[:i]), Type: v.Type()}
+			}
+			v = v.Elem()
+		}
+
+		if !seg.isKey {
+			if v.Kind() != reflect.Struct {
+				return reflect.Value{}, &UnsupportedTypeError{Path: formatPath(segs[:i]), Op: op, Type: v.Type()}
+			}
+
+			field := structSpecForType(v.Type(), tagName, z.naming).Field(seg.name)
+			if field == nil {
+				return reflect.Value{}, &UnknownFieldError{Path: formatPath(segs[:i+1]), Field: seg.name}
+			}
+
+			fv := fieldByIndex(v, field.Index(), false)
+			if !fv.IsValid() {
+				return reflect.Value{}, &NilStructError{Path: formatPath(segs[:i+1]), Type: field.Type()}
+			}
+			v = fv
+			tagName = subtagOf(field, tagName)
+			continue
+		}
+
+		switch v.Kind() {
+		case reflect.Slice, reflect.Array:
+			idx, err := strconv.Atoi(seg.name)
+			if err != nil {
+				return reflect.Value{}, pathErrorf(op, segs, i, "bad index '%v'", seg.name)
+			} else if idx < 0 || idx >= v.Len() {
+				return reflect.Value{}, pathErrorf(op, segs, i, "index out of range (len = %v)", v.Len())
+			}
+			v = v.Index(idx)
+
+		case reflect.Map:
+			key, err := parseString(seg.name, v.Type().Key())
+			if err != nil {
+				return reflect.Value{}, pathErrorf(op, segs, i, "bad map key: %v", err)
+			}
+
+			elem := v.MapIndex(key)
+			if !elem.IsValid() {
+				return reflect.Value{}, pathErrorf(op, segs, i, "no such key")
+			}
+			v = elem
+
+		default:
+			return reflect.Value{}, &UnsupportedTypeError{Path: formatPath(segs[:i]), Op: op, Type: v.Type()}
+		}
+	}
+	return v, nil
+}
+
+// Sets the value at `segs[i:]` relative to `v`, which must be settable.  Map elements aren't
+// settable, so they're copied out, updated, and then written back to the map.  `timeFormat` is the
+// "format" flag of the innermost struct field on the path so far.
+func (z *Structomancer) setPath(v reflect.Value, tagName, timeFormat string, segs []pathSegment, i int, value reflect.Value) error {
+	const op = "SetFieldValue"
+
+	if i == len(segs) {
+		if !value.IsValid() {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+
+		d := z.newDecodeState()
+		d.path = append(d.path, segs...)
+		d.timeFormat = timeFormat
+
+		converted, err := d.fromNativeValue(value, v.Type(), tagName)
+		if converted.IsValid() {
+			v.Set(converted)
+		}
+		return d.finish(err)
+	}
+
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	seg := segs[i]
+
+	if !seg.isKey {
+		if v.Kind() != reflect.Struct {
+			return &UnsupportedTypeError{Path: formatPath(segs[:i]), Op: op, Type: v.Type()}
+		}
+
+		field := structSpecForType(v.Type(), tagName, z.naming).Field(seg.name)
+		if field == nil {
+			return &UnknownFieldError{Path: formatPath(segs[:i+1]), Field: seg.name}
+		}
+		return z.setPath(fieldByIndex(v, field.Index(), true), subtagOf(field, tagName), field.timeFormat, segs, i+1, value)
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		idx, err := strconv.Atoi(seg.name)
+		if err != nil || idx < 0 {
+			return pathErrorf(op, segs, i, "bad index '%v'", seg.name)
+		}
+
+		if idx >= v.Len() {
+			if v.Kind() == reflect.Array {
+				return pathErrorf(op, segs, i, "index out of range (len = %v)", v.Len())
+			}
+			grown := reflect.MakeSlice(v.Type(), idx+1, idx+1)
+			reflect.Copy(grown, v)
+			v.Set(grown)
+		}
+		return z.setPath(v.Index(idx), tagName, timeFormat, segs, i+1, value)
+
+	case reflect.Map:
+		key, err := parseString(seg.name, v.Type().Key())
+		if err != nil {
+			return pathErrorf(op, segs, i, "bad map key: %v", err)
+		}
+
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+
+		if err := z.setPath(elem, tagName, timeFormat, segs, i+1, value); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+		return nil
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return &NilStructError{Path: formatPath(segs[:i]), Type: v.Type()}
+		}
+
+		// same deal as map elements: interfaces' contents aren't settable
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		if err := z.setPath(elem, tagName, timeFormat, segs, i, value); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+
+	default:
+		return &UnsupportedTypeError{Path: formatPath(segs[:i]), Op: op, Type: v.Type()}
+	}
+}
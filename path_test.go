@@ -0,0 +1,110 @@
+package structomancer_test
+
+import (
+	"errors"
+
+	"github.com/brynbellomy/go-structomancer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Paths", func() {
+	z := structomancer.New(&Keith{}, tagName)
+
+	newKeith := func() *Keith {
+		return &Keith{
+			InnerStruct:  InnerStruct{"xyzzy", []B{5, 6, 7}},
+			StructSlice:  []InnerStruct{{"zero", nil}, {"one", []B{1}}},
+			MapOfStructs: map[string]InnerStruct{"some-key": {"xyzzy", []B{8}}},
+		}
+	}
+
+	Context("when .GetFieldValue is called with a path", func() {
+		It("should walk nested structs, slices and maps using @tag subtags", func() {
+			k := newKeith()
+
+			v, err := z.GetFieldValue(k, "inner.bar[2]")
+			Expect(err).To(BeNil())
+			Expect(v).To(Equal(B(7)))
+
+			v, err = z.GetFieldValue(k, "structSlice[1].foo")
+			Expect(err).To(BeNil())
+			Expect(v).To(Equal("one"))
+
+			v, err = z.GetFieldValue(k, "mapOfStructs[some-key].bar[0]")
+			Expect(err).To(BeNil())
+			Expect(v).To(Equal(B(8)))
+		})
+
+		It("should report the path segment that failed", func() {
+			k := newKeith()
+
+			_, err := z.GetFieldValue(k, "structSlice[5].foo")
+			Expect(err).To(MatchError(ContainSubstring("'structSlice[5]'")))
+
+			_, err = z.GetFieldValue(k, "inner.nope")
+			Expect(err).To(MatchError(ContainSubstring("'inner.nope'")))
+
+			_, err = z.GetFieldValue(k, "mapOfStructs[missing].foo")
+			Expect(err).To(MatchError(ContainSubstring("'mapOfStructs[missing]'")))
+
+			var pathErr *structomancer.PathError
+			_, err = z.GetFieldValue(k, "inner.bar[x]")
+			Expect(errors.As(err, &pathErr)).To(BeTrue())
+			Expect(pathErr.Path).To(Equal("inner.bar[x]"))
+			Expect(pathErr.Op).To(Equal("GetFieldValue"))
+		})
+
+		It("should return a NilStructError for nil structs", func() {
+			var nilErr *structomancer.NilStructError
+
+			_, err := z.GetFieldValue(nil, "inner.foo")
+			Expect(errors.As(err, &nilErr)).To(BeTrue())
+
+			_, err = z.GetFieldValue((*Keith)(nil), "inner.foo")
+			Expect(errors.As(err, &nilErr)).To(BeTrue())
+
+			_, err = z.PointerToField(nil, "inner.foo")
+			Expect(errors.As(err, &nilErr)).To(BeTrue())
+		})
+
+		It("should reject malformed paths", func() {
+			k := newKeith()
+
+			for _, path := range []string{"structSlice[0]foo", "inner..foo", "structSlice[0", "[0].foo", "inner."} {
+				_, err := z.GetFieldValue(k, path)
+				Expect(err).To(MatchError(ContainSubstring("bad path")), path)
+			}
+		})
+	})
+
+	Context("when .SetFieldValue is called with a path", func() {
+		It("should set nested values, allocating maps and growing slices as needed", func() {
+			k := &Keith{}
+
+			Expect(z.SetFieldValue(k, "inner.bar[1]", 9)).To(Succeed())
+			Expect(k.InnerStruct.Bar).To(Equal([]B{0, 9}))
+
+			Expect(z.SetFieldValue(k, "mapOfStructs[a].foo", "quux")).To(Succeed())
+			Expect(k.MapOfStructs).To(Equal(map[string]InnerStruct{"a": {Foo: "quux"}}))
+
+			Expect(z.SetFieldValue(k, "mapOfBools[yes]", true)).To(Succeed())
+			Expect(k.MapOfBools).To(Equal(map[Name]bool{"yes": true}))
+		})
+	})
+
+	Context("when .PointerToField is called with a path", func() {
+		It("should return a pointer to the nested value", func() {
+			k := newKeith()
+
+			p, err := z.PointerToField(k, "structSlice[0].foo")
+			Expect(err).To(BeNil())
+			*(p.(*string)) = "changed"
+			Expect(k.StructSlice[0].Foo).To(Equal("changed"))
+
+			_, err = z.PointerToField(k, "mapOfStructs[some-key].foo")
+			Expect(err).NotTo(BeNil())
+		})
+	})
+})
@@ -14,6 +14,7 @@ type (
 	specCacheKey struct {
 		tagName    string
 		structType reflect.Type
+		naming     *NamingStrategy
 	}
 )
 
@@ -26,12 +27,12 @@ func newSpecCache() *specCache {
 	}
 }
 
-func structSpecForType(t reflect.Type, tagName string) (spec *structSpec) {
+func structSpecForType(t reflect.Type, tagName string, naming *NamingStrategy) (spec *structSpec) {
 	if !(IsStructType(t) || IsStructPtrType(t)) {
 		panic("structomancer: unsupported type " + t.String())
 	}
 
-	key := specCacheKey{structType: t, tagName: tagName}
+	key := specCacheKey{structType: t, tagName: tagName, naming: naming}
 
 	cache.RLock()
 	spec, found := cache.specs[key]
@@ -41,8 +42,10 @@ func structSpecForType(t reflect.Type, tagName string) (spec *structSpec) {
 		return
 	}
 
+	// build the spec before taking the lock, since newStructSpec panics on bad struct tags
+	spec = newStructSpec(t, tagName, naming)
+
 	cache.Lock()
-	spec = newStructSpec(t, tagName)
 	cache.specs[key] = spec
 	cache.Unlock()
 
@@ -4,11 +4,16 @@ import "reflect"
 
 type (
 	FieldSpec struct {
-		name  string
-		tag   tag
-		index []int
-		rType reflect.Type
-		rKind reflect.Kind
+		name       string
+		tag        tag
+		index      []int
+		rType      reflect.Type
+		rKind      reflect.Kind
+		hasDefault bool     // whether the field has a (valid) "default" flag
+		timeFormat string   // resolved from the "format" flag, if there is one
+		aliases    []string // parsed from the "alias" flag, if there is one
+		rules      []validationRule
+		crossRules []crossFieldRule
 	}
 
 	IFieldSpec interface {
@@ -26,22 +31,41 @@ type (
 	}
 )
 
-func newFieldSpec(field reflect.StructField, tagName string) *FieldSpec {
+func newFieldSpec(field reflect.StructField, index []int, tagName string, naming *NamingStrategy) *FieldSpec {
 	// it's worth caching the reflect.StructField data, as calling `.Field(...)` on a reflect.Value
 	// creates the reflect.StructField from scratch every time
-	return &FieldSpec{
+	fSpec := &FieldSpec{
 		name:  field.Name,
 		rType: field.Type,
 		rKind: field.Type.Kind(),
-		index: field.Index,
-		tag:   newTag(field, tagName),
+		index: index,
+		tag:   newTag(field, tagName, naming),
 	}
+	fSpec.rules = parseValidationRules(field, fSpec.tag)
+	fSpec.crossRules = parseCrossFieldRules(field, fSpec.tag)
+
+	fSpec.aliases = parseAliases(fSpec.tag)
+
+	if format, hasFormat := fSpec.FlagValue("format"); hasFormat {
+		fSpec.timeFormat = resolveTimeFormat(format)
+	}
+
+	// parse default values up front, so that bad ones are caught as early as possible
+	if def, hasDefault := fSpec.FlagValue("default"); hasDefault {
+		if _, err := parseString(def, field.Type); err != nil {
+			panic("structomancer: bad default value for field " + field.Name + ": " + err.Error())
+		}
+		fSpec.hasDefault = true
+	}
+	return fSpec
 }
 
 func (f *FieldSpec) Name() string {
 	return f.name
 }
 
+// Returns the field's index sequence, relative to the outermost struct.  Fields promoted from embedded
+// structs have more than one element (see reflect.Value.FieldByIndex).
 func (f *FieldSpec) Index() []int {
 	return f.index
 }
@@ -73,3 +97,52 @@ func (f *FieldSpec) IsFlagged(flag string) bool {
 func (f *FieldSpec) FlagValue(flag string) (string, bool) {
 	return f.tag.FlagValue(flag)
 }
+
+// Returns the value given by the field's "default" flag (converted to the field's type), and whether
+// it has one.  Lists (for slice and array fields) are separated with "|", i.e. `api:"x, default=1|2"`.
+// The value is parsed afresh on every call, so slices, maps and pointers are never shared between the
+// structs that it's applied to.
+func (f *FieldSpec) Default() (reflect.Value, bool) {
+	if !f.hasDefault {
+		return reflect.Value{}, false
+	}
+	def, _ := f.FlagValue("default")
+	val, _ := parseString(def, f.rType) // already checked by newFieldSpec
+	return val, true
+}
+
+// Returns the tag name used for the field's value if it's a struct: the one given by its "@tag" flag,
+// or otherwise the one the field itself was parsed with.
+func (f *FieldSpec) Subtag() string {
+	return subtagOf(f, f.TagName())
+}
+
+// Returns the other names the field accepts when decoding (see the "alias" flag).
+func (f *FieldSpec) Aliases() []string {
+	return f.aliases
+}
+
+// Returns true if the field is marked with the "required" flag, meaning that decoding fails when
+// its key is missing from the input (or nil).
+func (f *FieldSpec) IsRequired() bool {
+	return f.tag.IsFlagged("required")
+}
+
+// Returns the field described by `index` in the struct `v`.  Unlike reflect.Value.FieldByIndex, this
+// doesn't panic on nil embedded struct pointers.  If `alloc` is true, they're allocated as needed
+// (which requires `v` to be settable); otherwise, an invalid reflect.Value is returned.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
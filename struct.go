@@ -10,13 +10,17 @@ type (
 		rType          reflect.Type
 		rKind          reflect.Kind
 		tagName        string
+		naming         *NamingStrategy
 		fields         map[string]*FieldSpec
 		fieldsByGoName map[string]*FieldSpec
-		fieldNames     []string // cached
+		fieldNames     []string          // cached
+		aliases        map[string]string // alias -> nickname
+		ignoredNames   map[string]bool   // the default names of fields marked with "-"
+		requiredFields []*FieldSpec      // cached
 	}
 )
 
-func newStructSpec(t reflect.Type, tagName string) *structSpec {
+func newStructSpec(t reflect.Type, tagName string, naming *NamingStrategy) *structSpec {
 	if !(IsStructType(t) || IsStructPtrType(t)) {
 		panic("structomancer: unsupported type " + t.String())
 	}
@@ -30,34 +34,53 @@ func newStructSpec(t reflect.Type, tagName string) *structSpec {
 		panic("structomancer: unsupported type " + t.String())
 	}
 
-	var fields []reflect.StructField
-	for i := 0; i < st.NumField(); i++ {
-		field := st.Field(i)
-
-		// skip fields marked with "-", just like the json package
-		if tag := field.Tag.Get(tagName); strings.HasPrefix(tag, "-") {
-			continue
-		}
-		fields = append(fields, field)
-	}
+	ignoredNames := make(map[string]bool)
+	fields := dominantFields(collectFields(st, tagName, naming, nil, map[reflect.Type]bool{}, ignoredNames))
 
 	fieldMap := make(map[string]*FieldSpec, len(fields))
-	fieldsByGoName := make(map[string]*FieldSpec, len(fields))
 	fieldNames := make([]string, len(fields))
-	for i, field := range fields {
-		fSpec := newFieldSpec(field, tagName)
+	var requiredFields []*FieldSpec
+	for i, fSpec := range fields {
 		fieldMap[fSpec.Nickname()] = fSpec
-		fieldsByGoName[fSpec.Name()] = fSpec
 		fieldNames[i] = fSpec.Nickname()
+		if fSpec.IsRequired() {
+			requiredFields = append(requiredFields, fSpec)
+		}
+	}
+
+	aliases := make(map[string]string)
+	for _, fSpec := range fields {
+		for _, alias := range fSpec.aliases {
+			if other, exists := aliases[alias]; exists || fieldMap[alias] != nil {
+				if !exists {
+					other = alias
+				}
+				panic("structomancer: alias '" + alias + "' of field " + fSpec.Name() + " conflicts with field '" + other + "'")
+			}
+			aliases[alias] = fSpec.Nickname()
+		}
+	}
+
+	// cross-field validation rules can only reference siblings once they're all known
+	for _, fSpec := range fields {
+		for _, rule := range fSpec.crossRules {
+			if fieldMap[rule.other] == nil {
+				panic("structomancer: validation rule '" + rule.name + "=" + rule.param + "' for field " + fSpec.Name() + " references unknown field '" + rule.other + "'")
+			}
+		}
 	}
 
 	return &structSpec{
 		rType:          t,
 		rKind:          t.Kind(),
 		tagName:        tagName,
+		naming:         naming,
 		fields:         fieldMap,
 		fieldNames:     fieldNames,
-		fieldsByGoName: fieldsByGoName,
+		aliases:        aliases,
+		fieldsByGoName: fieldsByGoName(fields),
+		ignoredNames:   ignoredNames,
+		requiredFields: requiredFields,
 	}
 }
 
@@ -94,6 +117,16 @@ func (s *structSpec) FieldByGoName(fname string) *FieldSpec {
 	}
 }
 
+// Returns true if `name` is the Go name of a field that's explicitly ignored by its tag ("-").
+func (s *structSpec) IsIgnoredField(name string) bool {
+	return s.ignoredNames[name] && s.Field(name) == nil
+}
+
+// Returns the fields marked with the "required" flag.
+func (s *structSpec) RequiredFields() []*FieldSpec {
+	return s.requiredFields
+}
+
 // Returns the number of exported fields recognized by Structomancer in the struct type.
 func (s *structSpec) NumFields() int {
 	return len(s.Fields())
@@ -103,3 +136,128 @@ func (s *structSpec) NumFields() int {
 func (s *structSpec) FieldNames() []string {
 	return s.fieldNames
 }
+
+// Returns a FieldSpec for each exported field in `st`.  Embedded structs (and pointers to structs)
+// that aren't given a nickname by their tag are flattened into the result, just like the json package
+// does.
+func collectFields(st reflect.Type, tagName string, naming *NamingStrategy, index []int, visited map[reflect.Type]bool, ignoredNames map[string]bool) []*FieldSpec {
+	// guard against embedding cycles, i.e. `type A struct { *A }`
+	if visited[st] {
+		return nil
+	}
+	visited[st] = true
+	defer delete(visited, st)
+
+	var fields []*FieldSpec
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+
+		// skip fields marked with "-", just like the json package
+		if tag := field.Tag.Get(tagName); strings.HasPrefix(tag, "-") {
+			ignoredNames[naming.Rename(field.Name)] = true
+			continue
+		}
+
+		// unexported fields can't be read or written through reflection, so only embedded ones (whose
+		// exported fields are promoted) are of any use
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		fSpec := newFieldSpec(field, fieldIndex, tagName, naming)
+
+		if field.Anonymous {
+			isUnexported := field.PkgPath != ""
+
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct && !fSpec.tag.named {
+				// we can't allocate unexported embedded pointers, so there's no way to set their fields
+				if isUnexported && field.Type.Kind() == reflect.Ptr {
+					continue
+				}
+				fields = append(fields, collectFields(ft, tagName, naming, fieldIndex, visited, ignoredNames)...)
+				continue
+
+			} else if isUnexported {
+				continue
+			}
+		}
+
+		fields = append(fields, fSpec)
+	}
+	return fields
+}
+
+// Applies Go's shadowing rules to the fields returned by collectFields.  When several fields share a
+// nickname, the shallowest one wins.  If more than one sits at that depth, the one whose nickname was
+// explicitly given by its tag wins.  Otherwise, the nickname is ambiguous and all of them are dropped.
+func dominantFields(fields []*FieldSpec) []*FieldSpec {
+	byNickname := make(map[string][]*FieldSpec, len(fields))
+	for _, f := range fields {
+		byNickname[f.Nickname()] = append(byNickname[f.Nickname()], f)
+	}
+
+	dominant := make([]*FieldSpec, 0, len(byNickname))
+	for _, f := range fields {
+		if dominantField(byNickname[f.Nickname()]) == f {
+			dominant = append(dominant, f)
+		}
+	}
+	return dominant
+}
+
+// Returns a map from the Go name of each of `fields` to the field, applying Go's shadowing rules to
+// fields promoted from embedded structs: the shallowest field with a given name wins, and if more than
+// one sits at that depth, the name is ambiguous and is left out.
+func fieldsByGoName(fields []*FieldSpec) map[string]*FieldSpec {
+	byGoName := make(map[string]*FieldSpec, len(fields))
+	ambiguous := make(map[string]bool)
+	for _, f := range fields {
+		prev, exists := byGoName[f.Name()]
+		if !exists || len(f.index) < len(prev.index) {
+			byGoName[f.Name()] = f
+			delete(ambiguous, f.Name())
+		} else if len(f.index) == len(prev.index) {
+			ambiguous[f.Name()] = true
+		}
+	}
+
+	for name := range ambiguous {
+		delete(byGoName, name)
+	}
+	return byGoName
+}
+
+func dominantField(fields []*FieldSpec) *FieldSpec {
+	minDepth := len(fields[0].index)
+	for _, f := range fields[1:] {
+		if len(f.index) < minDepth {
+			minDepth = len(f.index)
+		}
+	}
+
+	var shallowest, tagged []*FieldSpec
+	for _, f := range fields {
+		if len(f.index) == minDepth {
+			shallowest = append(shallowest, f)
+			if f.tag.named {
+				tagged = append(tagged, f)
+			}
+		}
+	}
+
+	if len(shallowest) == 1 {
+		return shallowest[0]
+	} else if len(tagged) == 1 {
+		return tagged[0]
+	}
+	return nil
+}
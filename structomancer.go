@@ -2,8 +2,6 @@ package structomancer
 
 import (
 	"reflect"
-
-	"github.com/pkg/errors"
 )
 
 type (
@@ -12,22 +10,29 @@ type (
 		tagName string
 
 		fieldEncoders, fieldDecoders map[string]FieldCoderFunc
+		typeCoders                   map[reflect.Type]typeCoder
+		decodeOpts                   DecodeOptions
+		naming                       *NamingStrategy
 	}
 
 	FieldCoderFunc func(interface{}) (interface{}, error)
 )
 
-func New(specimen interface{}, tagName string) *Structomancer {
-	return NewWithType(reflect.TypeOf(specimen), tagName)
+func New(specimen interface{}, tagName string, opts ...Option) *Structomancer {
+	return NewWithType(reflect.TypeOf(specimen), tagName, opts...)
 }
 
-func NewWithType(t reflect.Type, tagName string) *Structomancer {
-	return &Structomancer{
+func NewWithType(t reflect.Type, tagName string, opts ...Option) *Structomancer {
+	z := &Structomancer{
 		tagName:       tagName,
-		structSpec:    structSpecForType(t, tagName),
 		fieldEncoders: make(map[string]FieldCoderFunc),
 		fieldDecoders: make(map[string]FieldCoderFunc),
 	}
+	for _, opt := range opts {
+		opt(z)
+	}
+	z.structSpec = structSpecForType(t, tagName, z.naming)
+	return z
 }
 
 // Sets the function used to encode the given field to a native Go value.
@@ -40,6 +45,41 @@ func (z *Structomancer) SetFieldDecoder(fname string, decoder FieldCoderFunc) {
 	z.fieldDecoders[fname] = decoder
 }
 
+// Returns the function used to encode the given field to a native Go value, if one has been set.
+func (z *Structomancer) FieldEncoder(fname string) (FieldCoderFunc, bool) {
+	encoder, exists := z.fieldEncoders[fname]
+	return encoder, exists
+}
+
+// Returns the function used to decode the given field from a native Go value, if one has been set.
+func (z *Structomancer) FieldDecoder(fname string) (FieldCoderFunc, bool) {
+	decoder, exists := z.fieldDecoders[fname]
+	return decoder, exists
+}
+
+// Returns the NamingStrategy given to New or NewWithType, or nil if there wasn't one.
+func (z *Structomancer) NamingStrategy() *NamingStrategy {
+	return z.naming
+}
+
+// Sets the options used by MapToStruct and SetFieldValue (and their V counterparts).
+func (z *Structomancer) SetDecodeOptions(opts DecodeOptions) {
+	z.decodeOpts = opts
+}
+
+// Returns the options used by MapToStruct and SetFieldValue.
+func (z *Structomancer) DecodeOptions() DecodeOptions {
+	return z.decodeOpts
+}
+
+// Returns a copy of the Structomancer that uses `opts` as its decode options.  The copy shares the
+// original's field encoders/decoders and type coders.
+func (z *Structomancer) WithDecodeOptions(opts DecodeOptions) *Structomancer {
+	clone := *z
+	clone.decodeOpts = opts
+	return &clone
+}
+
 // Returns a pointer to a new, empty instance of the struct, regardless of whether the struct type
 // is a struct or a pointer to a struct.  This method is appropriate for creating an instance that is
 // guaranteed to be addressable (see reflect.Value.CanAddr()).
@@ -64,7 +104,11 @@ func (z *Structomancer) IsKnownField(fname string) bool {
 	return z.Field(fname) != nil
 }
 
-// Returns the value of the struct field with the given nickname.
+// Returns the value of the struct field with the given nickname.  Instead of a nickname, `fnickname`
+// can be a path into nested structs, slices, arrays and maps, made up of field nicknames separated by
+// dots and of indices or map keys in brackets, i.e. `structSlice[0].bar[2]`.  Nested structs'
+// nicknames are resolved using each field's "@tag" subtag.  Field encoders are only applied to
+// single fields, not to paths.
 func (z *Structomancer) GetFieldValue(aStruct interface{}, fnickname string) (interface{}, error) {
 	fv, err := z.GetFieldValueV(reflect.ValueOf(aStruct), fnickname)
 	if err != nil {
@@ -73,32 +117,42 @@ func (z *Structomancer) GetFieldValue(aStruct interface{}, fnickname string) (in
 	return fv.Interface(), nil
 }
 
-// Returns a reflect.Value containing the value of the struct field with the given nickname.
+// Returns a reflect.Value containing the value of the struct field with the given nickname (or at the
+// given path).  See GetFieldValue.
 func (z *Structomancer) GetFieldValueV(v reflect.Value, fnickname string) (reflect.Value, error) {
+	if z.isPath(fnickname) {
+		return z.getPathValueV(v, fnickname)
+	}
+	return z.getFieldValueV(v, fnickname, "")
+}
+
+// `structPath` is the path of the struct contained by `v`, which is used to report errors.
+func (z *Structomancer) getFieldValueV(v reflect.Value, fnickname string, structPath string) (reflect.Value, error) {
 	field := z.Field(fnickname)
 	if field == nil {
-		return reflect.Value{}, errors.New("structomancer.GetFieldValue: unknown field '" + fnickname + "'")
+		return reflect.Value{}, &UnknownFieldError{Path: joinPath(structPath, fnickname), Field: fnickname}
 	}
 
-	if v.Kind() == reflect.Ptr && (!v.Elem().IsValid() || v.IsNil()) {
-		return reflect.Value{}, errors.New("structomancer.GetFieldValue: aStruct argument cannot be nil")
+	if !v.IsValid() {
+		return reflect.Value{}, &NilStructError{Path: structPath, Type: z.Type()}
+	} else if v.Kind() == reflect.Ptr && (!v.Elem().IsValid() || v.IsNil()) {
+		return reflect.Value{}, &NilStructError{Path: structPath, Type: v.Type()}
+	} else if !IsStructPtrValue(v) && !IsStructValue(v) {
+		return reflect.Value{}, &UnsupportedTypeError{Path: structPath, Op: "GetFieldValue", Type: v.Type()}
 	}
 
-	var fieldVal reflect.Value
-	if IsStructPtrValue(v) {
-		fieldVal = reflect.Indirect(v).FieldByName(field.Name())
-	} else if IsStructValue(v) {
-		fieldVal = v.FieldByName(field.Name())
-	} else {
-		return reflect.Value{}, errors.New("structomancer.GetFieldValue: Unsupported type '" + v.Type().String() + "'")
+	fieldVal := fieldByIndex(reflect.Indirect(v), field.Index(), false)
+
+	// the field was promoted from a nil embedded struct pointer
+	if !fieldVal.IsValid() {
+		fieldVal = reflect.Zero(field.Type())
 	}
 
 	if encoder, exists := z.fieldEncoders[fnickname]; exists {
 		fv := fieldVal.Interface()
 		fv, err := encoder(fv)
 		if err != nil {
-			// @@TODO
-			return reflect.Value{}, errors.New("structomancer.GetFieldValue: error calling user encoder: " + err.Error())
+			return reflect.Value{}, &UserCoderError{Path: joinPath(structPath, fnickname), Err: err}
 		}
 		fieldVal = reflect.ValueOf(fv)
 	}
@@ -107,7 +161,9 @@ func (z *Structomancer) GetFieldValueV(v reflect.Value, fnickname string) (refle
 }
 
 // Sets `field` to `value` in `aStruct`, converting the value if it is of a convertible type.  If it
-// is not convertible to the receiving field's type, this function returns an error.
+// is not convertible to the receiving field's type, this function returns an error.  `fname` may also
+// be one of the field's aliases, or a path like the ones accepted by GetFieldValue, in which case nil
+// pointers and maps along the way are allocated and slices are grown to fit the given indices.
 func (z *Structomancer) SetFieldValue(aStruct interface{}, fname string, value interface{}) error {
 	return z.SetFieldValueV(reflect.ValueOf(aStruct), fname, reflect.ValueOf(value))
 }
@@ -116,50 +172,62 @@ func (z *Structomancer) SetFieldValue(aStruct interface{}, fname string, value i
 // convertible type.  If it is not convertible to the receiving field's type, this function returns
 // an error.
 func (z *Structomancer) SetFieldValueV(sv reflect.Value, fname string, value reflect.Value) error {
-	field := z.Field(fname)
-	if field == nil {
-		return errors.New("structomancer.SetFieldValue: unknown field '" + fname + "'")
+	if z.isPath(fname) {
+		return z.setPathValueV(sv, fname, value)
 	}
 
-	if sv.Kind() == reflect.Ptr && (!sv.Elem().IsValid() || sv.IsNil()) {
-		return errors.New("structomancer.SetFieldValue: struct argument cannot be nil")
+	d := z.newDecodeState()
+	return d.finish(z.setFieldValueV(sv, fname, value, d))
+}
+
+func (z *Structomancer) setFieldValueV(sv reflect.Value, fname string, value reflect.Value, d *decodeState) error {
+	if nickname, isAlias := z.aliases[fname]; isAlias {
+		fname = nickname
 	}
 
-	var fieldVal reflect.Value
-	if IsStructPtrValue(sv) {
-		fieldVal = reflect.Indirect(sv).FieldByName(field.Name())
-	} else if IsStructValue(sv) {
-		fieldVal = sv.FieldByName(field.Name())
-	} else {
-		return errors.New("structomancer.GetFieldValue: unsupported type '" + sv.Type().String() + "'")
+	if !sv.IsValid() || (sv.Kind() == reflect.Ptr && (!sv.Elem().IsValid() || sv.IsNil())) {
+		return &NilStructError{Path: d.currentPath(), Type: z.Type()}
+	} else if !IsStructPtrValue(sv) && !IsStructValue(sv) {
+		return &UnsupportedTypeError{Path: d.currentPath(), Op: "SetFieldValue", Type: sv.Type()}
 	}
 
+	d.pushField(fname)
+	defer d.pop()
+
+	field := z.Field(fname)
+	if field == nil {
+		return &UnknownFieldError{Path: d.currentPath(), Field: fname}
+	}
+
+	fieldVal := fieldByIndex(reflect.Indirect(sv), field.Index(), true)
+
 	if decode, ok := z.fieldDecoders[fname]; ok {
 		val, err := decode(value.Interface())
 		if err != nil {
-			return err
+			return d.fail(&UserCoderError{Path: d.currentPath(), Err: err})
 		}
 		value = reflect.ValueOf(val)
 
 	} else {
-		var subtag string
-		if sub, isDefined := field.FlagValue("@tag"); isDefined {
-			subtag = sub
-		} else {
-			subtag = z.tagName
-		}
-
 		var err error
-		value, err = FromNativeValue(value, field.Type(), subtag)
+		outerFormat := d.timeFormat
+		d.timeFormat = field.timeFormat
+		value, err = d.fromNativeValue(value, field.Type(), z.subtagFor(field))
+		d.timeFormat = outerFormat
 		if err != nil {
 			return err
 		}
 	}
 
-	fieldVal.Set(value)
+	if value.IsValid() {
+		fieldVal.Set(value)
+	}
 	return nil
 }
 
+// Returns a pointer to the struct field with the given nickname, or to the value at the given path
+// (see GetFieldValue).  Map elements aren't addressable, so paths that end inside of a map value
+// return an error.
 func (z *Structomancer) PointerToField(aStruct interface{}, fieldName string) (interface{}, error) {
 	v, err := z.PointerToFieldV(reflect.ValueOf(aStruct), fieldName)
 	if err != nil {
@@ -168,20 +236,28 @@ func (z *Structomancer) PointerToField(aStruct interface{}, fieldName string) (i
 	return v.Interface(), nil
 }
 
+// Returns a reflect.Value containing a pointer to the struct field with the given nickname (or to
+// the value at the given path).  See PointerToField.
 func (z *Structomancer) PointerToFieldV(aStruct reflect.Value, fieldName string) (reflect.Value, error) {
+	if z.isPath(fieldName) {
+		return z.pointerToPathV(aStruct, fieldName)
+	}
+
 	if !z.IsKnownField(fieldName) {
-		return reflect.Value{}, errors.Errorf("unknown struct field: %v", fieldName)
+		return reflect.Value{}, &UnknownFieldError{Path: fieldName, Field: fieldName}
+	} else if !aStruct.IsValid() || (aStruct.Kind() == reflect.Ptr && aStruct.IsNil()) {
+		return reflect.Value{}, &NilStructError{Type: z.Type()}
 	}
-	realName := z.Field(fieldName).Name()
+	index := z.Field(fieldName).Index()
 
 	if z.Kind() == reflect.Ptr {
-		return aStruct.Elem().FieldByName(realName).Addr(), nil
+		return fieldByIndex(aStruct.Elem(), index, true).Addr(), nil
 	}
-	return aStruct.FieldByName(realName).Addr(), nil
+	return fieldByIndex(aStruct, index, true).Addr(), nil
 }
 
 // Returns a map containing the contents of `aStruct`, taking into account the field tags defined for
-// the current `tagName`.
+// the current `tagName`.  Empty fields flagged with "omitempty" are left out of the map.
 func (z *Structomancer) StructToMap(aStruct interface{}) (map[string]interface{}, error) {
 	return z.StructToMapV(reflect.ValueOf(aStruct))
 }
@@ -202,6 +278,12 @@ func (z *Structomancer) StructToMapV(aStruct reflect.Value) (map[string]interfac
 			return nil, err
 		}
 
+		// fields marked "omitempty" are left out of the map entirely when they're empty, just like the
+		// json package
+		if field.IsFlagged("omitempty") && IsZero(rval) {
+			continue
+		}
+
 		var val interface{}
 		if rval.IsValid() && rval.CanInterface() {
 			val = rval.Interface()
@@ -223,38 +305,228 @@ func (z *Structomancer) StructToMapV(aStruct reflect.Value) (map[string]interfac
 	return fieldMap, nil
 }
 
-// Returns a struct created by decoding the contents of `fields`.
+// Returns a map containing the contents of `aStruct`, like StructToMap, except that each field value is
+// also converted with ToNativeValue (using the field's "@tag" subtag, if it has one).  The result is a
+// tree made up entirely of maps, slices, and scalars, which can be handed straight to any serializer.
+func (z *Structomancer) StructToNativeMap(aStruct interface{}) (map[string]interface{}, error) {
+	return z.StructToNativeMapV(reflect.ValueOf(aStruct))
+}
+
+// Returns a map containing the contents of `aStruct`, like StructToMapV, except that each field value
+// is also converted with ToNativeValue (using the field's "@tag" subtag, if it has one).
+func (z *Structomancer) StructToNativeMapV(aStruct reflect.Value) (map[string]interface{}, error) {
+	return z.structToNativeMapV(aStruct, z.newEncodeState())
+}
+
+func (z *Structomancer) structToNativeMapV(aStruct reflect.Value, e *encodeState) (map[string]interface{}, error) {
+	fieldMap := make(map[string]interface{}, z.NumFields())
+
+	for fname, field := range z.Fields() {
+		rval, err := z.getFieldValueV(aStruct, fname, e.currentPath())
+		if err != nil {
+			return nil, err
+		}
+
+		if field.IsFlagged("omitempty") && IsZero(rval) {
+			continue
+		}
+
+		outerFormat := e.timeFormat
+		e.timeFormat = field.timeFormat
+		e.pushField(fname)
+		nv, err := e.toNativeValue(rval, z.subtagFor(field))
+		e.pop()
+		e.timeFormat = outerFormat
+		if err != nil {
+			return nil, err
+		}
+
+		fieldMap[fname] = nativeInterface(nv)
+	}
+	return fieldMap, nil
+}
+
+// Returns a struct created by decoding the contents of `fields`.  If the CollectErrors decode option
+// is set, the partially decoded struct is returned alongside any errors.
 func (z *Structomancer) MapToStruct(fields map[string]interface{}) (interface{}, error) {
 	sv, err := z.MapToStructV(fields)
-	if err != nil {
+	if !sv.IsValid() {
 		return nil, err
 	}
 
-	return sv.Interface(), nil
+	return sv.Interface(), err
 }
 
 // Returns a reflect.Value containing a struct created by decoding the contents of `fields`.
 func (z *Structomancer) MapToStructV(fields map[string]interface{}) (reflect.Value, error) {
+	d := z.newDecodeState()
+	sv, err := z.mapToStructV(fields, d)
+	return sv, d.finish(err)
+}
+
+func (z *Structomancer) mapToStructV(fields map[string]interface{}, d *decodeState) (reflect.Value, error) {
 	aStruct := z.MakeEmptyV()
+	applyDefaults(aStruct.Elem(), z.structSpec, z.tagName)
 
-	for fname, mapVal := range fields {
-		if !z.IsKnownField(fname) {
-			continue
-		} else if mapVal == nil || IsZero(reflect.ValueOf(mapVal)) {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+
+	chosen, err := d.resolveKeys(z.structSpec, keys)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	present := make(map[string]bool, len(chosen))
+	for _, fname := range z.FieldNames() {
+		key, isPresent := chosen[fname]
+		if !isPresent {
 			continue
 		}
 
-		err := z.SetFieldValueV(aStruct, fname, reflect.ValueOf(mapVal))
+		err := z.decodeField(aStruct, fname, reflect.ValueOf(fields[key]), present, d)
 		if err != nil {
 			return reflect.Value{}, err
 		}
 	}
 
-	// if the structomancer's type is a struct, not a struct pointer, dereference the pointer so we
-	// return the right type
+	d.checkRequired(z.structSpec, func(fname string) bool { return present[fname] })
+	return z.structResult(aStruct), nil
+}
+
+// Returns a reflect.Value containing a struct created by decoding the key/value pairs returned by
+// `next`, which returns false once there are none left.  This is MapToStructV without the map, for
+// decoders that read keys and values one at a time: when a field is named by more than one key, the
+// AliasConflicts option is applied as each key arrives, and otherwise the last key wins.  Errors
+// returned by `next` are returned as-is.
+func (z *Structomancer) FieldsToStructV(next func() (key string, value reflect.Value, ok bool, err error)) (reflect.Value, error) {
+	d := z.newDecodeState()
+	aStruct := z.MakeEmptyV()
+	applyDefaults(aStruct.Elem(), z.structSpec, z.tagName)
+
+	seen := make(map[string]keyMatch)
+	present := make(map[string]bool)
+	for {
+		key, value, ok, err := next()
+		if err != nil {
+			return reflect.Value{}, err
+		} else if !ok {
+			break
+		}
+
+		fname, rank, isKnown := d.opts.KeyMatcher.match(z.structSpec, key)
+		if !isKnown {
+			d.unknownKey(z.structSpec, key)
+			continue
+		}
+
+		match := keyMatch{key: key, rank: rank}
+		if prev, isSeen := seen[fname]; isSeen {
+			if replace, err := d.replacesKey(fname, prev, match); err != nil {
+				return reflect.Value{}, d.finish(err)
+			} else if !replace {
+				continue
+			}
+		}
+		seen[fname] = match
+
+		if err := z.decodeField(aStruct, fname, value, present, d); err != nil {
+			return reflect.Value{}, d.finish(err)
+		}
+	}
+
+	d.checkRequired(z.structSpec, func(fname string) bool { return present[fname] })
+	return z.structResult(aStruct), d.finish(nil)
+}
+
+// Decodes `value` into the field `fname` of `aStruct`, recording whether it was present.
+func (z *Structomancer) decodeField(aStruct reflect.Value, fname string, value reflect.Value, present map[string]bool, d *decodeState) error {
+	if value.Kind() == reflect.Interface {
+		value = reflect.ValueOf(value.Interface())
+	}
+
+	present[fname] = value.IsValid()
+	if !value.IsValid() {
+		return nil
+	} else if !z.Field(fname).hasDefault && IsZero(value) {
+		// explicit zero values still have to override defaults
+		return nil
+	}
+	return z.setFieldValueV(aStruct, fname, value, d)
+}
+
+// If the structomancer's type is a struct, not a struct pointer, dereferences `aStruct` so that we
+// return the right type.
+func (z *Structomancer) structResult(aStruct reflect.Value) reflect.Value {
 	if IsStructType(z.Type()) {
-		return aStruct.Elem(), nil
-	} else {
-		return aStruct, nil
+		return aStruct.Elem()
+	}
+	return aStruct
+}
+
+// Sets each zero-valued field of `aStruct` that has a "default" flag to its default value, descending
+// into nested structs (using their "@tag" subtags).  `aStruct` must be a pointer to a struct.
+func (z *Structomancer) ApplyDefaults(aStruct interface{}) error {
+	return z.ApplyDefaultsV(reflect.ValueOf(aStruct))
+}
+
+// Sets each zero-valued field of the struct pointed to by `sv` that has a "default" flag to its
+// default value.  See ApplyDefaults.
+func (z *Structomancer) ApplyDefaultsV(sv reflect.Value) error {
+	if !sv.IsValid() || sv.Kind() != reflect.Ptr || sv.IsNil() {
+		return &NilStructError{Type: z.Type()}
+	} else if !IsStructPtrValue(sv) {
+		return &UnsupportedTypeError{Op: "ApplyDefaults", Type: sv.Type()}
+	}
+
+	applyDefaults(sv.Elem(), z.structSpec, z.tagName)
+	return nil
+}
+
+func applyDefaults(sv reflect.Value, s *structSpec, tagName string) {
+	for _, fname := range s.FieldNames() {
+		field := s.Field(fname)
+
+		fv := fieldByIndex(sv, field.Index(), false)
+		if fv.IsValid() && !fv.CanSet() {
+			continue
+		}
+
+		if def, hasDefault := field.Default(); hasDefault {
+			if IsZero(fv) {
+				fieldByIndex(sv, field.Index(), true).Set(def)
+			}
+			continue
+		}
+
+		if !fv.IsValid() {
+			continue
+		} else if fv.Kind() == reflect.Ptr && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+
+		if fv.Kind() == reflect.Struct {
+			subtag := subtagOf(field, tagName)
+			applyDefaults(fv, structSpecForType(fv.Type(), subtag, s.naming), subtag)
+		}
+	}
+}
+
+func (z *Structomancer) newDecodeState() *decodeState {
+	return newDecodeState(z.decodeOpts, z.typeCoders, z.naming)
+}
+
+// Returns the tag name used to (de)serialize the contents of the given field: the one given by its
+// "@tag" flag, if it has one, or else the Structomancer's own tag name.
+func (z *Structomancer) subtagFor(field *FieldSpec) string {
+	return subtagOf(field, z.tagName)
+}
+
+// Returns the tag name given by the field's "@tag" flag, or `tagName` if it doesn't have one.
+func subtagOf(field *FieldSpec, tagName string) string {
+	if subtag, isDefined := field.FlagValue("@tag"); isDefined {
+		return subtag
 	}
+	return tagName
 }
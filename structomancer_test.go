@@ -2,6 +2,7 @@ package structomancer_test
 
 import (
 	"reflect"
+	"time"
 
 	"github.com/brynbellomy/go-structomancer"
 
@@ -30,6 +31,36 @@ type (
 		Bar []B    `weezy:"bar"`
 	}
 
+	Base struct {
+		ID      string `xyzzy:"id"`
+		Created int64  `xyzzy:"created"`
+	}
+
+	Audited struct {
+		Created int64  `xyzzy:"created"`
+		Editor  string `xyzzy:"editor"`
+	}
+
+	Embedder struct {
+		Base
+		*Audited
+		Title string `xyzzy:"title"`
+		ID    string `xyzzy:"id"`
+	}
+
+	Sparse struct {
+		Title string        `xyzzy:"title, omitempty"`
+		Count int           `xyzzy:"count, omitempty"`
+		Tags  []string      `xyzzy:"tags, omitempty"`
+		Inner []SparseInner `xyzzy:"inner, omitempty, @tag=weezy"`
+		Kept  int           `xyzzy:"kept"`
+	}
+
+	SparseInner struct {
+		Foo string `weezy:"foo, omitempty"`
+		Bar int    `weezy:"bar"`
+	}
+
 	Name string
 	Age  uint64
 	B    uint64
@@ -209,6 +240,19 @@ var _ = Describe("Structomancer", func() {
 				Expect(k).To(Equal(expected[i]))
 			}
 		})
+
+		It("should leave nil slice and array elements zeroed", func() {
+			z := structomancer.New(&Keith{}, tagName)
+			k, err := z.MapToStruct(map[string]interface{}{
+				"structSlice": []interface{}{nil, map[string]interface{}{"foo": "xyzzy"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k.(*Keith).StructSlice).To(Equal([]InnerStruct{{}, {"xyzzy", nil}}))
+
+			arr, err := structomancer.FromNativeValue(reflect.ValueOf([]interface{}{nil, 2}), reflect.TypeOf([2]int{}), tagName)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(arr.Interface()).To(Equal([2]int{0, 2}))
+		})
 	})
 
 	Context("when .StructToMap is called", func() {
@@ -314,4 +358,118 @@ var _ = Describe("Structomancer", func() {
 			}
 		})
 	})
+
+	Context("when the struct has embedded structs", func() {
+		z := structomancer.New(&Embedder{}, tagName)
+
+		It("should promote the embedded structs' fields, applying Go's shadowing rules", func() {
+			Expect(z.FieldNames()).To(Equal([]string{"editor", "title", "id"}))
+			Expect(z.Field("id").Index()).To(Equal([]int{3}))
+			Expect(z.Field("editor").Index()).To(Equal([]int{1, 1}))
+			Expect(z.IsKnownField("created")).To(BeFalse())
+		})
+
+		It("should find promoted fields by Go name, leaving out ambiguous names", func() {
+			type Left struct {
+				ID   string `xyzzy:"leftID"`
+				Name string `xyzzy:"leftName"`
+			}
+			type Right struct {
+				ID string `xyzzy:"rightID"`
+			}
+			type Both struct {
+				Left
+				Right
+				Name string `xyzzy:"name"`
+			}
+
+			z := structomancer.New(&Both{}, tagName)
+			Expect(z.Field("leftID")).NotTo(BeNil())
+			Expect(z.Field("rightID")).NotTo(BeNil())
+			Expect(z.FieldByGoName("ID")).To(BeNil())
+			Expect(z.FieldByGoName("Name").Nickname()).To(Equal("name"))
+		})
+
+		It("should get promoted fields, treating nil embedded pointers as zero values", func() {
+			x := &Embedder{Title: "hi"}
+
+			v, err := z.GetFieldValue(x, "editor")
+			Expect(err).To(BeNil())
+			Expect(v).To(Equal(""))
+
+			m, err := z.StructToMap(x)
+			Expect(err).To(BeNil())
+			Expect(m).To(Equal(map[string]interface{}{"editor": "", "title": "hi", "id": ""}))
+		})
+
+		It("should allocate nil embedded pointers when setting promoted fields", func() {
+			x, err := z.MapToStruct(map[string]interface{}{"editor": "bryn", "id": "xyzzy"})
+			Expect(err).To(BeNil())
+			Expect(x).To(Equal(&Embedder{Audited: &Audited{Editor: "bryn"}, ID: "xyzzy"}))
+		})
+	})
+
+	Context("when .StructToMap is called on a struct with \"omitempty\" fields", func() {
+		z := structomancer.New(&Sparse{}, tagName)
+
+		It("should leave empty fields out of the map", func() {
+			m, err := z.StructToMap(&Sparse{Count: 3})
+			Expect(err).To(BeNil())
+			Expect(m).To(Equal(map[string]interface{}{"count": 3, "kept": 0}))
+		})
+
+		It("should apply to nested structs converted by ToNativeValue using the field's @tag subtag", func() {
+			inner := []SparseInner{{Bar: 1}, {Foo: "x"}}
+			v, err := structomancer.ToNativeValue(reflect.ValueOf(inner), "weezy")
+			Expect(err).To(BeNil())
+			Expect(v.Interface()).To(Equal([]interface{}{
+				map[string]interface{}{"bar": 1},
+				map[string]interface{}{"foo": "x", "bar": 0},
+			}))
+		})
+	})
+
+	Context("when .StructToNativeMap is called", func() {
+		It("should convert every field into a tree of native maps, slices and scalars", func() {
+			z := structomancer.New(&Keith{}, tagName)
+
+			m, err := z.StructToNativeMap(&Keith{
+				Name:         Name("keith richards"),
+				Age:          Age(429),
+				InnerStruct:  InnerStruct{"xyzzy", []B{5, 6}},
+				StructSlice:  []InnerStruct{{"xyzzy", nil}},
+				MapOfStructs: map[string]InnerStruct{"some-key": {"xyzzy", []B{7}}},
+				MapOfBools:   map[Name]bool{"one": true},
+			})
+			Expect(err).To(BeNil())
+
+			Expect(m).To(Equal(map[string]interface{}{
+				"name":           "keith richards",
+				"age":            uint64(429),
+				"inner":          map[string]interface{}{"foo": "xyzzy", "bar": []interface{}{uint64(5), uint64(6)}},
+				"structSlice":    []interface{}{map[string]interface{}{"foo": "xyzzy", "bar": []interface{}{}}},
+				"interfaceSlice": []interface{}{},
+				"mapOfStructs": map[string]interface{}{
+					"some-key": map[string]interface{}{"foo": "xyzzy", "bar": []interface{}{uint64(7)}},
+				},
+				"mapOfBools": map[string]interface{}{"one": true},
+				"nested":     []interface{}{},
+			}))
+		})
+
+		It("should leave unexported fields out", func() {
+			type Account struct {
+				Name     string    `xyzzy:"name"`
+				password string    `xyzzy:"password"`
+				created  time.Time `xyzzy:"created"`
+			}
+			z := structomancer.New(&Account{}, tagName)
+			Expect(z.FieldNames()).To(Equal([]string{"name"}))
+
+			m, err := z.StructToNativeMap(&Account{Name: "n", password: "hunter2", created: time.Now()})
+			Expect(err).To(BeNil())
+			Expect(m).To(Equal(map[string]interface{}{"name": "n"}))
+			Expect(z.StructToMap(&Account{Name: "n", password: "hunter2"})).To(Equal(map[string]interface{}{"name": "n"}))
+		})
+	})
 })
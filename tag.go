@@ -9,13 +9,14 @@ type (
 	tag struct {
 		tagName  string // the name of the tag itself, i.e., "api" in `api:"myField,data,blah"`
 		nickname string // the first element of the comma-separated tag contents
+		named    bool   // whether the nickname was given explicitly (rather than defaulting to the Go name)
 		tagParts        // the rest of the elements of the tag string after the `nickname`
 	}
 
 	tagParts []string
 )
 
-func newTag(field reflect.StructField, tagName string) tag {
+func newTag(field reflect.StructField, tagName string, naming *NamingStrategy) tag {
 	parts := strings.Split(field.Tag.Get(tagName), ",")
 
 	// ignore spaces — they can be helpful for readability
@@ -25,9 +26,10 @@ func newTag(field reflect.StructField, tagName string) tag {
 
 	// the first component of the tag string is the "serialized" (i.e., non-struct, i.e., JSON-y) name of the field
 	nickname := parts[0]
-	// if it isn't specified, we give it a default name (which is just its Go name)
-	if nickname == "" {
-		nickname = field.Name
+	named := nickname != ""
+	// if it isn't specified, we give it a default name (its Go name, unless there's a naming strategy)
+	if !named {
+		nickname = naming.Rename(field.Name)
 	}
 
 	// parts only contains the parts after the serialized name
@@ -36,6 +38,7 @@ func newTag(field reflect.StructField, tagName string) tag {
 	return tag{
 		tagName:  tagName,
 		nickname: nickname,
+		named:    named,
 		tagParts: tagParts(parts),
 	}
 }
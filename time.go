@@ -0,0 +1,164 @@
+package structomancer
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Named layouts that can be given to the "format" flag in place of a literal layout.
+var timeLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"StampMilli":  time.StampMilli,
+	"StampMicro":  time.StampMicro,
+	"StampNano":   time.StampNano,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+var errUnsupportedTime = errors.New("unsupported time value")
+
+const (
+	timeFormatUnix      = "unix"
+	timeFormatUnixMilli = "unixmilli"
+	timeFormatUnixNano  = "unixnano"
+
+	// tells the encoder to write durations as strings like "1h30m0s" rather than as nanoseconds
+	durationFormatString = "string"
+)
+
+// Resolves the value of a "format" flag to a layout that can be passed to time.Format/time.Parse
+// (or to one of the special unix* formats).
+func resolveTimeFormat(format string) string {
+	if layout, exists := timeLayouts[format]; exists {
+		return layout
+	}
+	return format
+}
+
+// Encodes time.Time values according to `format` (RFC 3339 by default), and time.Duration values
+// as nanoseconds (or as strings, if `format` is "string").  Returns false for any other type.
+func encodeTime(v reflect.Value, format string) (reflect.Value, bool) {
+	switch v.Type() {
+	case timeType:
+		t := v.Interface().(time.Time)
+		switch format {
+		case timeFormatUnix:
+			return reflect.ValueOf(t.Unix()), true
+		case timeFormatUnixMilli:
+			return reflect.ValueOf(t.UnixMilli()), true
+		case timeFormatUnixNano:
+			return reflect.ValueOf(t.UnixNano()), true
+		case "":
+			return reflect.ValueOf(t.Format(time.RFC3339Nano)), true
+		default:
+			return reflect.ValueOf(t.Format(format)), true
+		}
+
+	case durationType:
+		if format == durationFormatString {
+			return reflect.ValueOf(v.Interface().(time.Duration).String()), true
+		}
+		return reflect.ValueOf(v.Int()), true
+	}
+	return reflect.Value{}, false
+}
+
+// Decodes time.Time values from strings in the given format (RFC 3339 by default) or, for the unix*
+// formats, from numbers.  time.Duration values are decoded from strings like "1h30m" or from numeric
+// nanoseconds.  Returns false for any other destination type.
+func (d *decodeState) decodeTime(nv reflect.Value, destType reflect.Type, format string) (reflect.Value, bool, error) {
+	if !nv.IsValid() || nv.Type() == destType {
+		return reflect.Value{}, false, nil
+	}
+
+	switch destType {
+	case timeType:
+		var t time.Time
+		var err error
+
+		// weak mode accepts numeric timestamps written as strings
+		if d.opts.WeaklyTypedInput && isUnixTimeFormat(format) && nv.Kind() == reflect.String {
+			if n, err := strconv.ParseInt(strings.TrimSpace(nv.String()), 10, 64); err == nil {
+				nv = reflect.ValueOf(n)
+			} else if f, err := strconv.ParseFloat(strings.TrimSpace(nv.String()), 64); err == nil {
+				nv = reflect.ValueOf(f)
+			}
+		}
+
+		switch {
+		case format == timeFormatUnix && isNumberKind(nv.Kind()):
+			if f, _ := toFloat(nv); !isIntKind(nv.Kind()) && !isUintKind(nv.Kind()) {
+				secs, frac := math.Modf(f)
+				t = time.Unix(int64(secs), int64(frac*1e9))
+			} else {
+				t = time.Unix(truncateInt64(nv), 0)
+			}
+		case format == timeFormatUnixMilli && isNumberKind(nv.Kind()):
+			t = time.UnixMilli(truncateInt64(nv))
+		case format == timeFormatUnixNano && isNumberKind(nv.Kind()):
+			t = time.Unix(0, truncateInt64(nv))
+		case nv.Kind() == reflect.String && !isUnixTimeFormat(format):
+			layout := format
+			if layout == "" {
+				layout = time.RFC3339Nano
+			}
+			t, err = time.Parse(layout, nv.String())
+		default:
+			err = errUnsupportedTime
+		}
+
+		if err != nil {
+			return reflect.Value{}, true, d.fail(&ConversionError{Path: d.currentPath(), From: nv.Type(), To: destType})
+		}
+		return reflect.ValueOf(t), true, nil
+
+	case durationType:
+		if nv.Kind() != reflect.String {
+			// numbers are converted like any other int64
+			return reflect.Value{}, false, nil
+		}
+
+		dur, err := time.ParseDuration(nv.String())
+		if err != nil {
+			return reflect.Value{}, true, d.fail(&ConversionError{Path: d.currentPath(), From: nv.Type(), To: destType})
+		}
+		return reflect.ValueOf(dur), true, nil
+	}
+	return reflect.Value{}, false, nil
+}
+
+func isUnixTimeFormat(format string) bool {
+	return format == timeFormatUnix || format == timeFormatUnixMilli || format == timeFormatUnixNano
+}
+
+// Returns the numeric value `v` as an int64, truncating floats.
+func truncateInt64(v reflect.Value) int64 {
+	switch {
+	case isIntKind(v.Kind()):
+		return v.Int()
+	case isUintKind(v.Kind()):
+		return int64(v.Uint())
+	default:
+		return int64(v.Float())
+	}
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	return isIntKind(k) || isUintKind(k) || k == reflect.Float32 || k == reflect.Float64
+}
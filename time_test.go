@@ -0,0 +1,115 @@
+package structomancer_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/brynbellomy/go-structomancer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Times and durations", func() {
+	type Event struct {
+		At       time.Time       `xyzzy:"at"`
+		Day      time.Time       `xyzzy:"day, format=DateOnly"`
+		Clock    time.Time       `xyzzy:"clock, format=15:04"`
+		Stamp    time.Time       `xyzzy:"stamp, format=unix"`
+		Millis   time.Time       `xyzzy:"millis, format=unixmilli"`
+		History  []time.Time     `xyzzy:"history, format=unix"`
+		Deadline *time.Time      `xyzzy:"deadline"`
+		Timeout  time.Duration   `xyzzy:"timeout"`
+		Interval time.Duration   `xyzzy:"interval, format=string"`
+		Backoff  []time.Duration `xyzzy:"backoff"`
+	}
+
+	at := time.Date(2021, time.March, 4, 5, 6, 7, 800, time.UTC)
+	deadline := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	event := Event{
+		At:       at,
+		Day:      time.Date(2021, time.March, 4, 0, 0, 0, 0, time.UTC),
+		Clock:    time.Date(0, time.January, 1, 5, 6, 0, 0, time.UTC),
+		Stamp:    time.Unix(1614834367, 0),
+		Millis:   time.UnixMilli(1614834367123),
+		History:  []time.Time{time.Unix(1, 0), time.Unix(2, 0)},
+		Deadline: &deadline,
+		Timeout:  90 * time.Second,
+		Interval: 5 * time.Minute,
+		Backoff:  []time.Duration{time.Second, 2 * time.Second},
+	}
+
+	native := map[string]interface{}{
+		"at":       "2021-03-04T05:06:07.0000008Z",
+		"day":      "2021-03-04",
+		"clock":    "05:06",
+		"stamp":    int64(1614834367),
+		"millis":   int64(1614834367123),
+		"history":  []interface{}{int64(1), int64(2)},
+		"deadline": "2022-01-01T00:00:00Z",
+		"timeout":  int64(90 * time.Second),
+		"interval": "5m0s",
+		"backoff":  []interface{}{int64(time.Second), int64(2 * time.Second)},
+	}
+
+	It("should encode times using RFC 3339 or the field's format, and durations as nanoseconds", func() {
+		z := structomancer.New(&Event{}, "xyzzy")
+		m, err := z.StructToNativeMap(&event)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m).To(Equal(native))
+	})
+
+	It("should decode what it encodes", func() {
+		z := structomancer.New(&Event{}, "xyzzy")
+		s, err := z.MapToStruct(native)
+		Expect(err).NotTo(HaveOccurred())
+
+		decoded := s.(*Event)
+		Expect(decoded.At.Equal(event.At)).To(BeTrue())
+		Expect(decoded.Day.Equal(event.Day)).To(BeTrue())
+		Expect(decoded.Clock.Equal(event.Clock)).To(BeTrue())
+		Expect(decoded.Stamp.Equal(event.Stamp)).To(BeTrue())
+		Expect(decoded.Millis.Equal(event.Millis)).To(BeTrue())
+		Expect(decoded.History[1].Equal(event.History[1])).To(BeTrue())
+		Expect(decoded.Deadline.Equal(deadline)).To(BeTrue())
+		Expect(decoded.Timeout).To(Equal(event.Timeout))
+		Expect(decoded.Interval).To(Equal(event.Interval))
+		Expect(decoded.Backoff).To(Equal(event.Backoff))
+	})
+
+	It("should decode durations from strings and unix times from floats", func() {
+		z := structomancer.New(&Event{}, "xyzzy")
+		s, err := z.MapToStruct(map[string]interface{}{
+			"timeout": "1h30m",
+			"stamp":   1.5,
+			"backoff": []interface{}{"1s", 2000000000},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		decoded := s.(*Event)
+		Expect(decoded.Timeout).To(Equal(90 * time.Minute))
+		Expect(decoded.Stamp.Equal(time.Unix(1, 500000000))).To(BeTrue())
+		Expect(decoded.Backoff).To(Equal([]time.Duration{time.Second, 2 * time.Second}))
+	})
+
+	It("should honor the field's format when setting values by path", func() {
+		z := structomancer.New(&Event{}, "xyzzy")
+		e := &Event{}
+		Expect(z.SetFieldValue(e, "history[0]", 3)).To(Succeed())
+		Expect(e.History[0].Equal(time.Unix(3, 0))).To(BeTrue())
+	})
+
+	It("should return a ConversionError for unparseable values", func() {
+		z := structomancer.New(&Event{}, "xyzzy")
+		_, err := z.MapToStruct(map[string]interface{}{"day": "yesterday"})
+
+		var convErr *structomancer.ConversionError
+		Expect(errors.As(err, &convErr)).To(BeTrue())
+		Expect(convErr.Path).To(Equal("day"))
+
+		_, err = z.MapToStruct(map[string]interface{}{"timeout": "soon"})
+		Expect(errors.As(err, &convErr)).To(BeTrue())
+		Expect(convErr.Path).To(Equal("timeout"))
+	})
+})
@@ -0,0 +1,70 @@
+package structomancer
+
+import (
+	"reflect"
+	"sync"
+)
+
+type (
+	// A pair of functions that (de)serialize every value of a given type (see RegisterTypeCoder).
+	// Either one may be nil.
+	typeCoder struct {
+		encoder FieldCoderFunc
+		decoder FieldCoderFunc
+	}
+
+	typeCoderRegistry struct {
+		sync.RWMutex
+		coders map[reflect.Type]typeCoder
+	}
+)
+
+var globalTypeCoders = &typeCoderRegistry{coders: make(map[reflect.Type]typeCoder)}
+
+// Registers functions used to encode values of type `t` to native Go values and to decode them from
+// native Go values, wherever that type appears (top-level fields, slice elements, map values,
+// pointers, etc.) in every struct handled by ToNativeValue and FromNativeValue.  Coders registered on
+// an individual Structomancer take precedence, as do per-field coders (see SetFieldEncoder).  Either
+// function may be nil.
+func RegisterTypeCoder(t reflect.Type, encoder, decoder FieldCoderFunc) {
+	globalTypeCoders.Lock()
+	defer globalTypeCoders.Unlock()
+	globalTypeCoders.coders[t] = typeCoder{encoder: encoder, decoder: decoder}
+}
+
+func (r *typeCoderRegistry) lookup(t reflect.Type) (typeCoder, bool) {
+	r.RLock()
+	defer r.RUnlock()
+	coder, exists := r.coders[t]
+	return coder, exists
+}
+
+// Registers functions used to encode and decode values of type `t` wherever they appear in the
+// structs handled by this Structomancer, including nested ones.  These take precedence over coders
+// registered with the package-level RegisterTypeCoder.  Either function may be nil.
+func (z *Structomancer) RegisterTypeCoder(t reflect.Type, encoder, decoder FieldCoderFunc) {
+	if z.typeCoders == nil {
+		z.typeCoders = make(map[reflect.Type]typeCoder)
+	}
+	z.typeCoders[t] = typeCoder{encoder: encoder, decoder: decoder}
+}
+
+// Returns the encoder for the given type, checking the per-instance coders before the global ones.
+func lookupTypeEncoder(instanceCoders map[reflect.Type]typeCoder, t reflect.Type) (FieldCoderFunc, bool) {
+	if coder, exists := instanceCoders[t]; exists && coder.encoder != nil {
+		return coder.encoder, true
+	} else if coder, exists := globalTypeCoders.lookup(t); exists && coder.encoder != nil {
+		return coder.encoder, true
+	}
+	return nil, false
+}
+
+// Returns the decoder for the given type, checking the per-instance coders before the global ones.
+func lookupTypeDecoder(instanceCoders map[reflect.Type]typeCoder, t reflect.Type) (FieldCoderFunc, bool) {
+	if coder, exists := instanceCoders[t]; exists && coder.decoder != nil {
+		return coder.decoder, true
+	} else if coder, exists := globalTypeCoders.lookup(t); exists && coder.decoder != nil {
+		return coder.decoder, true
+	}
+	return nil, false
+}
@@ -0,0 +1,157 @@
+package structomancer_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/brynbellomy/go-structomancer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type Money struct {
+	Cents int64
+}
+
+type Celsius struct {
+	Degrees float64
+}
+
+func init() {
+	structomancer.RegisterTypeCoder(reflect.TypeOf(Money{}),
+		func(x interface{}) (interface{}, error) {
+			m := x.(Money)
+			return fmt.Sprintf("%d.%02d", m.Cents/100, m.Cents%100), nil
+		},
+		func(x interface{}) (interface{}, error) {
+			s, isString := x.(string)
+			if !isString {
+				return nil, errors.New("money must be a string")
+			}
+			var dollars, cents int64
+			if _, err := fmt.Sscanf(s, "%d.%d", &dollars, &cents); err != nil {
+				return nil, err
+			}
+			return Money{Cents: dollars*100 + cents}, nil
+		},
+	)
+}
+
+var _ = Describe("Type coders", func() {
+	type Line struct {
+		Price Money `xyzzy:"price"`
+	}
+
+	type Invoice struct {
+		Total    Money            `xyzzy:"total"`
+		Discount *Money           `xyzzy:"discount"`
+		History  []Money          `xyzzy:"history"`
+		ByMonth  map[string]Money `xyzzy:"byMonth"`
+		Lines    []Line           `xyzzy:"lines"`
+		Temp     Celsius          `xyzzy:"temp"`
+	}
+
+	invoice := Invoice{
+		Total:    Money{Cents: 1234},
+		Discount: &Money{Cents: 50},
+		History:  []Money{{Cents: 100}, {Cents: 205}},
+		ByMonth:  map[string]Money{"jan": {Cents: 999}},
+		Lines:    []Line{{Price: Money{Cents: 1000}}},
+		Temp:     Celsius{Degrees: 21.5},
+	}
+
+	native := map[string]interface{}{
+		"total":    "12.34",
+		"discount": "0.50",
+		"history":  []interface{}{"1.00", "2.05"},
+		"byMonth":  map[string]interface{}{"jan": "9.99"},
+		"lines":    []interface{}{map[string]interface{}{"price": "10.00"}},
+		"temp":     map[string]interface{}{"Degrees": 21.5},
+	}
+
+	Context("registered globally", func() {
+		It("should encode the type wherever it appears", func() {
+			z := structomancer.New(&Invoice{}, "xyzzy")
+			m, err := z.StructToNativeMap(&invoice)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m).To(Equal(native))
+		})
+
+		It("should decode the type wherever it appears", func() {
+			z := structomancer.New(&Invoice{}, "xyzzy")
+			s, err := z.MapToStruct(native)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s).To(Equal(&invoice))
+		})
+
+		It("should apply to FromNativeValue", func() {
+			v, err := structomancer.FromNativeValue(reflect.ValueOf("3.07"), reflect.TypeOf(Money{}), "xyzzy")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v.Interface()).To(Equal(Money{Cents: 307}))
+		})
+
+		It("should report errors returned by the decoder along with their path", func() {
+			z := structomancer.New(&Invoice{}, "xyzzy")
+			_, err := z.MapToStruct(map[string]interface{}{"history": []interface{}{"1.00", 7}})
+
+			var coderErr *structomancer.UserCoderError
+			Expect(errors.As(err, &coderErr)).To(BeTrue())
+			Expect(coderErr.Path).To(Equal("history[1]"))
+		})
+	})
+
+	Context("registered on an instance", func() {
+		encodeCelsius := func(x interface{}) (interface{}, error) {
+			return fmt.Sprintf("%.1fC", x.(Celsius).Degrees), nil
+		}
+		decodeCelsius := func(x interface{}) (interface{}, error) {
+			var c Celsius
+			_, err := fmt.Sscanf(x.(string), "%fC", &c.Degrees)
+			return c, err
+		}
+
+		It("should apply only to that instance", func() {
+			z := structomancer.New(&Invoice{}, "xyzzy")
+			z.RegisterTypeCoder(reflect.TypeOf(Celsius{}), encodeCelsius, decodeCelsius)
+
+			m, err := z.StructToNativeMap(&invoice)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m["temp"]).To(Equal("21.5C"))
+
+			other := structomancer.New(&Invoice{}, "xyzzy")
+			m, err = other.StructToNativeMap(&invoice)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m["temp"]).To(Equal(map[string]interface{}{"Degrees": 21.5}))
+		})
+
+		It("should take precedence over global coders", func() {
+			z := structomancer.New(&Invoice{}, "xyzzy")
+			z.RegisterTypeCoder(reflect.TypeOf(Money{}), func(x interface{}) (interface{}, error) {
+				return x.(Money).Cents, nil
+			}, nil)
+
+			m, err := z.StructToNativeMap(&invoice)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m["total"]).To(Equal(int64(1234)))
+			Expect(m["lines"]).To(Equal([]interface{}{map[string]interface{}{"price": int64(1000)}}))
+
+			// the instance registered no decoder, so the global one is used
+			s, err := z.MapToStruct(map[string]interface{}{"total": "1.01"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s.(*Invoice).Total).To(Equal(Money{Cents: 101}))
+		})
+	})
+
+	It("should let field coders take precedence over type coders", func() {
+		z := structomancer.New(&Invoice{}, "xyzzy")
+		z.SetFieldDecoder("total", func(x interface{}) (interface{}, error) {
+			return Money{Cents: int64(x.(int))}, nil
+		})
+
+		s, err := z.MapToStruct(map[string]interface{}{"total": 42})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.(*Invoice).Total).To(Equal(Money{Cents: 42}))
+	})
+})
@@ -0,0 +1,76 @@
+package structomancer
+
+import "reflect"
+
+type (
+	// Typed wraps a Structomancer for the struct type T, so that callers don't have to type-assert the
+	// values it returns.  The untyped methods are still available through the embedded *Structomancer.
+	Typed[T any] struct {
+		*Structomancer
+	}
+)
+
+// Returns a Typed structomancer for the struct type T (not a pointer to it), using the given tag
+// name and options.  Like New, it shares the struct spec cache, so this is cheap to call repeatedly.
+func For[T any](tagName string, opts ...Option) *Typed[T] {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if !IsStructType(t) {
+		panic("structomancer: For[T] requires a struct type, got " + t.String())
+	}
+	return &Typed[T]{Structomancer: NewWithType(t, tagName, opts...)}
+}
+
+// Returns a pointer to a new, empty instance of T.
+func (z *Typed[T]) MakeEmpty() *T {
+	return z.MakeEmptyV().Interface().(*T)
+}
+
+// Returns a T created by decoding the contents of `fields`.
+func (z *Typed[T]) MapToStruct(fields map[string]interface{}) (T, error) {
+	v, err := z.MapToStructV(fields)
+	if !v.IsValid() {
+		var zero T
+		return zero, err
+	}
+	return v.Interface().(T), err
+}
+
+// Returns a map containing the contents of `aStruct`.  See Structomancer.StructToMap.
+func (z *Typed[T]) StructToMap(aStruct T) (map[string]interface{}, error) {
+	return z.StructToMapV(reflect.ValueOf(aStruct))
+}
+
+// Sets the field with the given nickname in `aStruct` to `value`.  See Structomancer.SetFieldValue.
+func (z *Typed[T]) Set(aStruct *T, fname string, value interface{}) error {
+	return z.SetFieldValueV(reflect.ValueOf(aStruct), fname, reflect.ValueOf(value))
+}
+
+// Returns the value of the field with the given nickname in `aStruct` as a V.  If the field's value
+// isn't assignable to V, it's converted with FromNativeValue.  If it's nil (i.e. because a field
+// encoder returned nil), the zero V is returned.
+func Get[V any, T any](z *Typed[T], aStruct *T, fname string) (V, error) {
+	var val V
+
+	fv, err := z.GetFieldValueV(reflect.ValueOf(aStruct), fname)
+	if err != nil {
+		return val, err
+	}
+
+	// field encoders can return nil, which comes back as an invalid Value
+	if !fv.IsValid() {
+		return val, nil
+	}
+
+	vType := reflect.TypeOf((*V)(nil)).Elem()
+	if !fv.Type().AssignableTo(vType) {
+		fv, err = FromNativeValue(fv, vType, z.subtagForPath(fname))
+		if err != nil {
+			return val, err
+		} else if !fv.IsValid() {
+			return val, nil
+		}
+	}
+
+	reflect.ValueOf(&val).Elem().Set(fv)
+	return val, nil
+}
@@ -0,0 +1,61 @@
+package structomancer_test
+
+import (
+	"github.com/brynbellomy/go-structomancer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Typed", func() {
+	z := structomancer.For[Keith](tagName)
+
+	It("should return a typed pointer from .MakeEmpty", func() {
+		var k *Keith = z.MakeEmpty()
+		Expect(k).To(Equal(&Keith{}))
+	})
+
+	It("should return a typed struct from .MapToStruct", func() {
+		k, err := z.MapToStruct(map[string]interface{}{"name": "keith", "age": 3})
+		Expect(err).To(BeNil())
+		Expect(k).To(Equal(Keith{Name: "keith", Age: 3}))
+	})
+
+	It("should get and set field values with .Get and .Set", func() {
+		k := z.MakeEmpty()
+		Expect(z.Set(k, "age", 42)).To(Succeed())
+
+		age, err := structomancer.Get[Age](z, k, "age")
+		Expect(err).To(BeNil())
+		Expect(age).To(Equal(Age(42)))
+
+		n, err := structomancer.Get[int](z, k, "age")
+		Expect(err).To(BeNil())
+		Expect(n).To(Equal(42))
+	})
+
+	It("should get values by path with .Get", func() {
+		k := &Keith{InnerStruct: InnerStruct{"xyzzy", []B{5}}, StructSlice: []InnerStruct{{"zero", nil}}}
+
+		n, err := structomancer.Get[int64](z, k, "inner.bar[0]")
+		Expect(err).To(BeNil())
+		Expect(n).To(Equal(int64(5)))
+
+		foo, err := structomancer.Get[string](z, k, "structSlice[0].foo")
+		Expect(err).To(BeNil())
+		Expect(foo).To(Equal("zero"))
+	})
+
+	It("should return the zero V from .Get when a field encoder returns nil", func() {
+		z := structomancer.For[Keith](tagName)
+		z.SetFieldEncoder("age", func(interface{}) (interface{}, error) { return nil, nil })
+
+		age, err := structomancer.Get[Age](z, &Keith{Age: 42}, "age")
+		Expect(err).To(BeNil())
+		Expect(age).To(Equal(Age(0)))
+	})
+
+	It("should panic when T isn't a struct type", func() {
+		Expect(func() { structomancer.For[*Keith](tagName) }).To(Panic())
+	})
+})
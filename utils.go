@@ -1,9 +1,9 @@
 package structomancer
 
 import (
-	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 )
 
 func IsZero(v reflect.Value) bool {
@@ -25,6 +25,11 @@ func IsZero(v reflect.Value) bool {
 		}
 		return z
 	default:
+		// values read from unexported fields (i.e., inside a time.Time) can't be compared directly
+		if !v.CanInterface() {
+			return v.IsZero()
+		}
+
 		// Compare other types directly:
 		z := reflect.Zero(v.Type())
 		return v.Interface() == z.Interface()
@@ -60,7 +65,30 @@ func IsStructPtrValue(v reflect.Value) bool {
 
 var stringType = reflect.TypeOf("")
 
+// Converts `v` to a native Go value: scalars become their underlying built-in types, slices and arrays
+// become []interface{}, and maps and structs become map[string]interface{} (using the tag name
+// `subtag` to encode any structs it encounters).  Pointers and interfaces are collapsed.  Values
+// implementing encoding.TextMarshaler become strings, and values implementing json.Marshaler become
+// whatever their JSON represents.
 func ToNativeValue(v reflect.Value, subtag string) (nv reflect.Value, err error) {
+	return newEncodeState(nil, nil).toNativeValue(v, subtag)
+}
+
+func (e *encodeState) toNativeValue(v reflect.Value, subtag string) (nv reflect.Value, err error) {
+	if v.IsValid() {
+		if encoder, exists := e.encoderFor(v.Type()); exists {
+			out, err := encoder(v.Interface())
+			if err != nil {
+				return reflect.Value{}, &UserCoderError{Path: e.currentPath(), Err: err}
+			}
+			return reflect.ValueOf(out), nil
+		} else if nv, isTime := encodeTime(v, e.timeFormat); isTime {
+			return nv, nil
+		} else if nv, isMarshaler, err := e.marshalNative(v); isMarshaler {
+			return nv, err
+		}
+	}
+
 	switch v.Kind() {
 	case reflect.Invalid:
 		return reflect.ValueOf(nil), nil
@@ -100,14 +128,14 @@ func ToNativeValue(v reflect.Value, subtag string) (nv reflect.Value, err error)
 	case reflect.Slice, reflect.Array:
 		dest := make([]interface{}, v.Len())
 		for i := 0; i < v.Len(); i++ {
-			src := reflect.ValueOf(v.Index(i).Interface())
-
-			nval, err := ToNativeValue(src, subtag)
+			e.pushKey(strconv.Itoa(i))
+			nval, err := e.toNativeValue(v.Index(i), subtag)
+			e.pop()
 			if err != nil {
 				return reflect.Value{}, err
 			}
 
-			dest[i] = nval.Interface()
+			dest[i] = nativeInterface(nval)
 		}
 		return reflect.ValueOf(dest), nil
 
@@ -116,37 +144,33 @@ func ToNativeValue(v reflect.Value, subtag string) (nv reflect.Value, err error)
 		ks := v.MapKeys()
 		for i := 0; i < len(ks); i++ {
 			// keys must be convertible to strings or this function will return an error
-			nvKey, err := ToNativeValue(ks[i], subtag)
+			nvKey, err := e.toNativeValue(ks[i], subtag)
 			if err != nil {
 				return reflect.Value{}, err
 			}
 
 			if nvKey.Type() != stringType {
-				return reflect.Value{}, errors.New("structomancer.ToNativeValue: cannot convert " + ks[i].Type().String() + " to string (for map key)")
+				return reflect.Value{}, &ConversionError{Path: e.currentPath(), From: ks[i].Type(), To: stringType}
 			}
 
 			strKey := nvKey.Interface().(string)
 
-			velem := v.MapIndex(ks[i])
-
-			if velem.Kind() == reflect.Interface {
-				// unwrap interfaces to expose the inner type
-				velem = reflect.ValueOf(velem.Interface())
-			}
-
-			// convert to native value
-			nval, err := ToNativeValue(velem, subtag)
+			// convert to native value (interfaces are unwrapped by ToNativeValue itself, so that it can
+			// see whether the interface type has been registered)
+			e.pushKey(strKey)
+			nval, err := e.toNativeValue(v.MapIndex(ks[i]), subtag)
+			e.pop()
 			if err != nil {
 				return reflect.Value{}, err
 			}
 
-			dest[strKey] = nval.Interface()
+			dest[strKey] = nativeInterface(nval)
 		}
 		return reflect.ValueOf(dest), nil
 
 	case reflect.Struct:
-		z := NewWithType(v.Type(), subtag)
-		m, err := z.StructToMapV(v)
+		z := NewWithType(v.Type(), subtag, WithNamingStrategy(e.naming))
+		m, err := z.structToNativeMapV(v, e)
 		if err != nil {
 			return reflect.Value{}, err
 		}
@@ -154,19 +178,36 @@ func ToNativeValue(v reflect.Value, subtag string) (nv reflect.Value, err error)
 
 	case reflect.Ptr:
 		if !v.IsValid() || v.IsNil() {
-			return v, nil
+			return reflect.ValueOf(nil), nil
 		}
 
 		// we simply collapse pointers when converting to native values
-		innerVal, err := ToNativeValue(v.Elem(), subtag)
+		innerVal, err := e.toNativeValue(v.Elem(), subtag)
 		if err != nil {
 			return reflect.Value{}, err
 		}
 		return innerVal, nil
 
 	case reflect.Interface:
-		// @@TODO?
-		return v, nil
+		if v.IsNil() {
+			return reflect.ValueOf(nil), nil
+		}
+
+		// unwrap interfaces to expose the inner type
+		inner, err := e.toNativeValue(v.Elem(), subtag)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		// values of registered interface types are tagged with the name of their concrete type
+		if spec := registeredInterface(v.Type()); spec != nil {
+			if name, exists := spec.nameOf(v.Elem().Type()); exists {
+				if m, isMap := nativeInterface(inner).(map[string]interface{}); isMap {
+					m[spec.discriminator] = name
+				}
+			}
+		}
+		return inner, nil
 
 	case reflect.Func,
 		reflect.Chan,
@@ -179,7 +220,63 @@ func ToNativeValue(v reflect.Value, subtag string) (nv reflect.Value, err error)
 	}
 }
 
+// Returns the contents of a reflect.Value returned by ToNativeValue, or nil if it's invalid (which is
+// how ToNativeValue represents nil).
+func nativeInterface(nv reflect.Value) interface{} {
+	if !nv.IsValid() || !nv.CanInterface() {
+		return nil
+	}
+	return nv.Interface()
+}
+
+// Converts the native value `nv` (i.e., one returned by ToNativeValue) to a value of type `destType`,
+// using the tag name `subtag` to decode any structs it encounters.  Types implementing
+// encoding.TextUnmarshaler are parsed from strings, and types implementing json.Unmarshaler are
+// decoded from the JSON representation of `nv`.
 func FromNativeValue(nv reflect.Value, destType reflect.Type, subtag string) (v reflect.Value, err error) {
+	return FromNativeValueWithOptions(nv, destType, subtag, DecodeOptions{})
+}
+
+// Like FromNativeValue, but decodes according to the given options.
+func FromNativeValueWithOptions(nv reflect.Value, destType reflect.Type, subtag string, opts DecodeOptions) (reflect.Value, error) {
+	d := newDecodeState(opts, nil, nil)
+	v, err := d.fromNativeValue(nv, destType, subtag)
+	return v, d.finish(err)
+}
+
+func (d *decodeState) fromNativeValue(nv reflect.Value, destType reflect.Type, subtag string) (v reflect.Value, err error) {
+	if nv.Kind() == reflect.Interface {
+		nv = reflect.ValueOf(nv.Interface())
+	}
+
+	if decoder, exists := d.decoderFor(destType); exists && nv.IsValid() {
+		out, err := decoder(nv.Interface())
+		if err != nil {
+			return reflect.Value{}, d.fail(&UserCoderError{Path: d.currentPath(), Err: err})
+		}
+
+		outVal := reflect.ValueOf(out)
+		if !outVal.IsValid() {
+			return reflect.Zero(destType), nil
+		} else if !outVal.Type().AssignableTo(destType) {
+			return reflect.Value{}, d.fail(&ConversionError{Path: d.currentPath(), From: outVal.Type(), To: destType})
+		}
+		return outVal, nil
+	} else if v, isBig, err := d.decodeBig(nv, destType); isBig {
+		return v, err
+	}
+
+	nv, isNumber, err := d.normalizeNumber(nv, destType)
+	if !isNumber {
+		return reflect.Value{}, err
+	}
+
+	if v, isTime, err := d.decodeTime(nv, destType, d.timeFormat); isTime {
+		return v, err
+	} else if v, isUnmarshaler, err := d.unmarshalNative(nv, destType); isUnmarshaler {
+		return v, err
+	}
+
 	switch destType.Kind() {
 	case reflect.Invalid:
 		panic("structomancer.FromNativeValue: bad destType parameter, destType.Kind() = reflect.Invalid")
@@ -207,14 +304,41 @@ func FromNativeValue(nv reflect.Value, destType reflect.Type, subtag string) (v
 
 		if nv.Type() == destType {
 			return nv, nil
+		}
+
+		if d.opts.WeaklyTypedInput {
+			if v, isCoerced, err := d.weakScalar(nv, destType); isCoerced {
+				return v, err
+			}
+		}
+
+		if destType.Kind() == reflect.String && (isIntKind(nv.Kind()) || isUintKind(nv.Kind())) {
+			// reflect happily converts integers into strings as runes (65 -> "A"), which is never
+			// what anyone decoding a map wants
+			return reflect.Value{}, d.fail(&ConversionError{Path: d.currentPath(), From: nv.Type(), To: destType})
 		} else if nv.Type().ConvertibleTo(destType) {
+			if !d.opts.AllowLossyNumbers {
+				if reason := lossyNumericConversion(nv, destType); reason != "" {
+					return reflect.Value{}, d.fail(&ConversionError{Path: d.currentPath(), From: nv.Type(), To: destType, Reason: reason})
+				}
+			}
 			return nv.Convert(destType), nil
 		} else {
-			return reflect.Value{}, errors.New("structomancer.FromNativeValue: cannot convert " + nv.Type().String() + " to " + destType.String())
+			return reflect.Value{}, d.fail(&ConversionError{Path: d.currentPath(), From: nv.Type(), To: destType})
 		}
 
 	case reflect.Slice:
-		slice := reflect.MakeSlice(destType, nv.Len(), nv.Cap())
+		if nv.Kind() == reflect.String && nv.Type().ConvertibleTo(destType) {
+			return nv.Convert(destType), nil
+		} else if nv.Kind() != reflect.Slice && nv.Kind() != reflect.Array {
+			if !d.opts.WeaklyTypedInput {
+				return reflect.Value{}, d.fail(&ConversionError{Path: d.currentPath(), From: nv.Type(), To: destType})
+			}
+			// weak mode accepts a single value where a slice is expected
+			nv = reflect.ValueOf([]interface{}{nv.Interface()})
+		}
+
+		slice := reflect.MakeSlice(destType, nv.Len(), nv.Len())
 
 		for i := 0; i < nv.Len(); i++ {
 			velem := nv.Index(i)
@@ -223,18 +347,33 @@ func FromNativeValue(nv reflect.Value, destType reflect.Type, subtag string) (v
 				// this strips any existing `interface{}` wrapper so we can see the real type
 				velem = reflect.ValueOf(velem.Interface())
 			}
+			if !velem.IsValid() {
+				// nil elements are left zeroed
+				continue
+			}
 
-			velem, err := FromNativeValue(velem, destType.Elem(), subtag)
+			d.pushKey(strconv.Itoa(i))
+			velem, err := d.fromNativeValue(velem, destType.Elem(), subtag)
+			d.pop()
 			if err != nil {
 				return reflect.Value{}, err
 			}
 
-			slice.Index(i).Set(velem)
+			if velem.IsValid() {
+				slice.Index(i).Set(velem)
+			}
 		}
 
 		return slice, nil
 
 	case reflect.Array:
+		if nv.Kind() != reflect.Slice && nv.Kind() != reflect.Array {
+			if !d.opts.WeaklyTypedInput {
+				return reflect.Value{}, d.fail(&ConversionError{Path: d.currentPath(), From: nv.Type(), To: destType})
+			}
+			nv = reflect.ValueOf([]interface{}{nv.Interface()})
+		}
+
 		n := nv.Len()
 		arrayType := reflect.ArrayOf(n, destType.Elem())
 		array := reflect.New(arrayType).Elem()
@@ -246,26 +385,34 @@ func FromNativeValue(nv reflect.Value, destType reflect.Type, subtag string) (v
 				// this strips any existing `interface{}` wrapper so we can see the real type
 				velem = reflect.ValueOf(velem.Interface())
 			}
+			if !velem.IsValid() {
+				// nil elements are left zeroed
+				continue
+			}
 
-			velem, err = FromNativeValue(velem, destType.Elem(), subtag)
+			d.pushKey(strconv.Itoa(i))
+			velem, err = d.fromNativeValue(velem, destType.Elem(), subtag)
+			d.pop()
 			if err != nil {
 				return reflect.Value{}, err
 			}
 
-			array.Index(i).Set(velem)
+			if velem.IsValid() {
+				array.Index(i).Set(velem)
+			}
 		}
 
 		return array, nil
 
 	case reflect.Struct:
-		z := NewWithType(destType, subtag)
+		z := NewWithType(destType, subtag, WithNamingStrategy(d.naming))
 
 		if nv.Kind() != reflect.Map {
-			return reflect.Value{}, errors.New("structomancer.FromNativeValue: cannot convert " + nv.Type().String() + " to " + destType.String())
+			return reflect.Value{}, d.fail(&ConversionError{Path: d.currentPath(), From: nv.Type(), To: destType})
 		}
 
 		if m, ok := nv.Interface().(map[string]interface{}); ok {
-			val, err := z.MapToStructV(m)
+			val, err := z.mapToStructV(m, d)
 			if err != nil {
 				return reflect.Value{}, err
 			}
@@ -275,6 +422,9 @@ func FromNativeValue(nv reflect.Value, destType reflect.Type, subtag string) (v
 			aStruct := z.MakeEmpty()
 			mapKeys := nv.MapKeys()
 			aStructVal := reflect.ValueOf(aStruct)
+			applyDefaults(aStructVal.Elem(), z.structSpec, subtag)
+			keys := make([]string, 0, len(mapKeys))
+			keyVals := make(map[string]reflect.Value, len(mapKeys))
 
 			for i := 0; i < len(mapKeys); i++ {
 				mapKey := mapKeys[i]
@@ -284,30 +434,46 @@ func FromNativeValue(nv reflect.Value, destType reflect.Type, subtag string) (v
 				}
 
 				if !mapKey.Type().ConvertibleTo(stringType) {
-					return reflect.Value{}, fmt.Errorf("structomancer.FromNativeValue: cannot deserialize struct from map with non-string keys (type = %v)", mapKey.Type().String())
+					return reflect.Value{}, d.fail(&ConversionError{Path: d.currentPath(), From: nv.Type(), To: destType})
 				}
 
-				fname := mapKey.Convert(stringType).Interface().(string)
-				if !z.IsKnownField(fname) {
-					continue
-				}
+				key := mapKey.Convert(stringType).Interface().(string)
+				keys = append(keys, key)
+				keyVals[key] = mapKeys[i]
+			}
+
+			chosen, err := d.resolveKeys(z.structSpec, keys)
+			if err != nil {
+				return reflect.Value{}, err
+			}
 
-				mapVal := nv.MapIndex(mapKey)
-				if !mapVal.IsValid() || IsZero(mapVal) {
+			present := make(map[string]bool, len(chosen))
+			for _, fname := range z.FieldNames() {
+				key, isPresent := chosen[fname]
+				if !isPresent {
 					continue
 				}
 
+				mapVal := nv.MapIndex(keyVals[key])
 				if mapVal.Kind() == reflect.Interface {
 					// this strips any existing `interface{}` wrapper so we can see the real type
 					mapVal = reflect.ValueOf(mapVal.Interface())
 				}
 
-				err := z.SetFieldValueV(aStructVal, fname, mapVal)
+				present[fname] = mapVal.IsValid()
+				if !mapVal.IsValid() {
+					continue
+				} else if !z.Field(fname).hasDefault && IsZero(mapVal) {
+					continue
+				}
+
+				err := z.setFieldValueV(aStructVal, fname, mapVal, d)
 				if err != nil {
 					return reflect.Value{}, err
 				}
-
 			}
+
+			d.checkRequired(z.structSpec, func(fname string) bool { return present[fname] })
 			return aStructVal.Elem(), nil
 		}
 
@@ -322,35 +488,63 @@ func FromNativeValue(nv reflect.Value, destType reflect.Type, subtag string) (v
 				velem = reflect.ValueOf(velem.Interface())
 			}
 
-			cnvKey, err := FromNativeValue(mapKeys[i], destType.Key(), subtag)
+			d.pushKey(fmt.Sprint(mapKeys[i].Interface()))
+			cnvKey, err := d.fromNativeValue(mapKeys[i], destType.Key(), subtag)
 			if err != nil {
+				d.pop()
 				return reflect.Value{}, err
 			}
 
-			velem, err = FromNativeValue(velem, destType.Elem(), subtag)
+			velem, err = d.fromNativeValue(velem, destType.Elem(), subtag)
+			d.pop()
 			if err != nil {
 				return reflect.Value{}, err
 			}
 
-			dest.SetMapIndex(cnvKey, velem)
+			if cnvKey.IsValid() && velem.IsValid() {
+				dest.SetMapIndex(cnvKey, velem)
+			}
 		}
 
 		return dest, nil
 
 	case reflect.Ptr:
-		if !nv.IsValid() || nv.IsNil() {
-			return nv, nil
+		if nv.Kind() == reflect.Interface {
+			nv = reflect.ValueOf(nv.Interface())
 		}
-		innerVal, err := FromNativeValue(nv.Elem(), destType.Elem(), subtag)
+
+		if !nv.IsValid() || (nv.Kind() == reflect.Ptr && nv.IsNil()) {
+			return reflect.Zero(destType), nil
+		}
+
+		// native values are usually not pointers (ToNativeValue collapses them), so those are decoded
+		// into the pointer's element type directly
+		if nv.Kind() == reflect.Ptr {
+			nv = nv.Elem()
+		}
+
+		innerVal, err := d.fromNativeValue(nv, destType.Elem(), subtag)
 		if err != nil {
 			return reflect.Value{}, err
 		}
 		ptrval := reflect.New(destType.Elem())
-		ptrval.Elem().Set(innerVal)
+		if innerVal.IsValid() {
+			ptrval.Elem().Set(innerVal)
+		}
 		return ptrval, nil
 
 	case reflect.Interface:
-		// @@TODO?
+		if nv.Kind() == reflect.Interface {
+			nv = reflect.ValueOf(nv.Interface())
+		}
+
+		if spec := registeredInterface(destType); spec != nil {
+			if nv.Kind() == reflect.Map {
+				return d.fromNativeInterface(nv, destType, spec, subtag)
+			} else if nv.IsValid() && !nv.Type().Implements(destType) {
+				return reflect.Value{}, d.fail(&ConversionError{Path: d.currentPath(), From: nv.Type(), To: destType})
+			}
+		}
 		return nv, nil
 
 	case reflect.Func,
@@ -0,0 +1,425 @@
+package structomancer
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type (
+	// A single failed validation rule.
+	Violation struct {
+		Path      string // the full path to the offending value, i.e. `structSlice[3].bar`
+		Rule      string // the name of the rule that failed, i.e. "min"
+		Param     string // the rule's parameter, if it has one, i.e. "3"
+		OtherPath string // for cross-field rules, the full path to the other field involved
+	}
+
+	// Returned by Validate, listing every rule that failed.
+	ValidationErrors []*Violation
+
+	// A validation rule parsed from a field's tag (i.e. `api:"name, min=3"`).
+	validationRule struct {
+		name  string
+		param string
+		check func(v reflect.Value) bool
+	}
+
+	// A validation rule that references a sibling field by its nickname (i.e. `api:"end, gtfield=start"`).
+	crossFieldRule struct {
+		name  string
+		param string
+		other string // the sibling's nickname
+		// conditional rules apply even to empty "omitempty" fields, since they're about whether the field is empty
+		conditional bool
+		check       func(v, other reflect.Value) bool
+	}
+)
+
+func (v *Violation) Error() string {
+	rule := v.Rule
+	if v.Param != "" {
+		rule += "=" + v.Param
+	}
+	msg := "structomancer: '" + v.Path + "' failed validation rule '" + rule + "'"
+	if v.OtherPath != "" {
+		msg += " (with '" + v.OtherPath + "')"
+	}
+	return msg
+}
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, v := range e {
+		msgs[i] = v.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Allows errors.As to find the individual Violations.
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, v := range e {
+		errs[i] = v
+	}
+	return errs
+}
+
+// Checks the contents of `aStruct` against the validation rules given by its fields' tags, descending
+// into nested structs (and slices, arrays, and maps of structs) using their "@tag" subtags.  Returns a
+// ValidationErrors listing every violation, or nil if there weren't any.
+//
+// The supported rules are:
+//   - `min=n`, `max=n`: bounds on a number's value, or on the length of a string, slice, array or map
+//   - `len=n`: the exact length of a string, slice, array or map
+//   - `pattern=re`: a regular expression that strings must match (it can't contain commas)
+//   - `oneof=a|b|c`: the allowed values, compared against the value's string representation
+//   - `email`, `url`: strings must be a bare email address or an absolute URL, respectively
+//
+// Rules can also compare a field with one of its siblings (referenced by nickname):
+//   - `eqfield=f`, `nefield=f`, `gtfield=f`, `gtefield=f`, `ltfield=f`, `ltefield=f`: compares
+//     numbers, strings and time.Times
+//   - `required_if=f:v`, `excluded_if=f:v`: the field must (or must not) be set when the string
+//     representation of sibling `f` is `v`
+//   - `required_with=f`, `excluded_with=f`: the field must (or must not) be set when sibling `f` is
+//     set
+//
+// Nil pointers are skipped, as are zero values of fields flagged with "omitempty".
+func (z *Structomancer) Validate(aStruct interface{}) error {
+	return z.ValidateV(reflect.ValueOf(aStruct))
+}
+
+// Checks the contents of the struct contained by `sv` against the validation rules given by its
+// fields' tags.  See Validate.
+func (z *Structomancer) ValidateV(sv reflect.Value) error {
+	if !sv.IsValid() {
+		return &NilStructError{Type: z.Type()}
+	} else if sv.Kind() == reflect.Ptr && (!sv.Elem().IsValid() || sv.IsNil()) {
+		return &NilStructError{Type: sv.Type()}
+	} else if !IsStructValue(reflect.Indirect(sv)) {
+		return &UnsupportedTypeError{Op: "Validate", Type: sv.Type()}
+	}
+
+	var violations ValidationErrors
+	validateStruct(reflect.Indirect(sv), z.structSpec, z.tagName, nil, &violations)
+	if len(violations) > 0 {
+		return violations
+	}
+	return nil
+}
+
+func validateStruct(sv reflect.Value, s *structSpec, tagName string, path []pathSegment, violations *ValidationErrors) {
+	for _, fname := range s.FieldNames() {
+		field := s.Field(fname)
+
+		fv := fieldByIndex(sv, field.Index(), false)
+		if fv.IsValid() && !fv.CanInterface() {
+			continue
+		}
+
+		fieldPath := append(path[:len(path):len(path)], pathSegment{name: fname})
+		isEmpty := field.IsFlagged("omitempty") && IsZero(fv)
+
+		for _, rule := range field.crossRules {
+			if isEmpty && !rule.conditional {
+				continue
+			}
+
+			other := fieldByIndex(sv, s.Field(rule.other).Index(), false)
+			if !rule.check(fv, other) {
+				otherPath := append(path[:len(path):len(path)], pathSegment{name: rule.other})
+				*violations = append(*violations, &Violation{
+					Path:      formatPath(fieldPath),
+					Rule:      rule.name,
+					Param:     rule.param,
+					OtherPath: formatPath(otherPath),
+				})
+			}
+		}
+
+		if !fv.IsValid() || isEmpty {
+			continue
+		}
+
+		if inner := reflect.Indirect(fv); inner.IsValid() {
+			for _, rule := range field.rules {
+				if !rule.check(inner) {
+					*violations = append(*violations, &Violation{Path: formatPath(fieldPath), Rule: rule.name, Param: rule.param})
+				}
+			}
+		}
+
+		validateNested(fv, subtagOf(field, tagName), s.naming, fieldPath, violations)
+	}
+}
+
+// Descends into any structs contained by `v`.
+func validateNested(v reflect.Value, tagName string, naming *NamingStrategy, path []pathSegment, violations *ValidationErrors) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		validateStruct(v, structSpecForType(v.Type(), tagName, naming), tagName, path, violations)
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			validateNested(v.Index(i), tagName, naming, append(path[:len(path):len(path)], pathSegment{name: strconv.Itoa(i), isKey: true}), violations)
+		}
+
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			validateNested(iter.Value(), tagName, naming, append(path[:len(path):len(path)], pathSegment{name: key, isKey: true}), violations)
+		}
+	}
+}
+
+// Parses the validation rules out of a field's tag.  Like bad default values, bad rules cause a panic
+// when the struct's spec is built.
+func parseValidationRules(field reflect.StructField, t tag) []validationRule {
+	ft := field.Type
+	for ft.Kind() == reflect.Ptr {
+		ft = ft.Elem()
+	}
+
+	badRule := func(part string) {
+		panic("structomancer: bad validation rule '" + part + "' for field " + field.Name + " (" + field.Type.String() + ")")
+	}
+
+	var rules []validationRule
+	for _, part := range t.tagParts {
+		name, param, _ := strings.Cut(part, "=")
+
+		var check func(v reflect.Value) bool
+		switch name {
+		case "min", "max", "len":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				badRule(part)
+			}
+
+			measure, ok := measureFunc(ft, name == "len")
+			if !ok {
+				badRule(part)
+			}
+
+			switch name {
+			case "min":
+				check = func(v reflect.Value) bool { return measure(v) >= n }
+			case "max":
+				check = func(v reflect.Value) bool { return measure(v) <= n }
+			case "len":
+				check = func(v reflect.Value) bool { return measure(v) == n }
+			}
+
+		case "pattern":
+			re, err := regexp.Compile(param)
+			if err != nil || ft.Kind() != reflect.String {
+				badRule(part)
+			}
+			check = func(v reflect.Value) bool { return re.MatchString(v.String()) }
+
+		case "oneof":
+			options := strings.Split(param, "|")
+			check = func(v reflect.Value) bool {
+				s := fmt.Sprint(v.Interface())
+				for _, opt := range options {
+					if s == opt {
+						return true
+					}
+				}
+				return false
+			}
+
+		case "email":
+			if ft.Kind() != reflect.String {
+				badRule(part)
+			}
+			check = func(v reflect.Value) bool {
+				addr, err := mail.ParseAddress(v.String())
+				return err == nil && addr.Address == v.String()
+			}
+
+		case "url":
+			if ft.Kind() != reflect.String {
+				badRule(part)
+			}
+			check = func(v reflect.Value) bool {
+				u, err := url.ParseRequestURI(v.String())
+				return err == nil && u.Scheme != "" && u.Host != ""
+			}
+
+		default:
+			continue
+		}
+
+		rules = append(rules, validationRule{name: name, param: param, check: check})
+	}
+	return rules
+}
+
+// Returns a function that measures values of type `t` for the min, max and len rules: numbers by
+// their value, and strings, slices, arrays and maps by their length.
+func measureFunc(t reflect.Type, lengthOnly bool) (func(v reflect.Value) float64, bool) {
+	switch t.Kind() {
+	case reflect.String:
+		return func(v reflect.Value) float64 { return float64(utf8.RuneCountInString(v.String())) }, true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return func(v reflect.Value) float64 { return float64(v.Len()) }, true
+	}
+
+	if lengthOnly {
+		return nil, false
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(v reflect.Value) float64 { return float64(v.Int()) }, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(v reflect.Value) float64 { return float64(v.Uint()) }, true
+	case reflect.Float32, reflect.Float64:
+		return func(v reflect.Value) float64 { return v.Float() }, true
+	}
+	return nil, false
+}
+
+// Parses the cross-field validation rules out of a field's tag.  The siblings they reference are
+// checked by newStructSpec, once all of the struct's fields are known.
+func parseCrossFieldRules(field reflect.StructField, t tag) []crossFieldRule {
+	var rules []crossFieldRule
+	for _, part := range t.tagParts {
+		name, param, _ := strings.Cut(part, "=")
+
+		rule := crossFieldRule{name: name, param: param, other: param}
+		switch name {
+		case "eqfield", "nefield", "gtfield", "gtefield", "ltfield", "ltefield":
+			rule.check = func(v, other reflect.Value) bool {
+				cmp, ok := compareValues(v, other)
+				if !ok {
+					// nil pointers and mismatched types are left to other rules
+					return true
+				}
+
+				switch name {
+				case "eqfield":
+					return cmp == 0
+				case "nefield":
+					return cmp != 0
+				case "gtfield":
+					return cmp > 0
+				case "gtefield":
+					return cmp >= 0
+				case "ltfield":
+					return cmp < 0
+				default:
+					return cmp <= 0
+				}
+			}
+
+		case "required_if", "excluded_if":
+			other, want, found := strings.Cut(param, ":")
+			if !found {
+				panic("structomancer: bad validation rule '" + part + "' for field " + field.Name + " (expected " + name + "=field:value)")
+			}
+
+			rule.other = other
+			rule.conditional = true
+			rule.check = func(v, otherVal reflect.Value) bool {
+				otherVal = reflect.Indirect(otherVal)
+				if !otherVal.IsValid() || fmt.Sprint(otherVal.Interface()) != want {
+					return true
+				}
+				return IsZero(v) == (name == "excluded_if")
+			}
+
+		case "required_with", "excluded_with":
+			rule.conditional = true
+			rule.check = func(v, other reflect.Value) bool {
+				if IsZero(other) {
+					return true
+				}
+				return IsZero(v) == (name == "excluded_with")
+			}
+
+		default:
+			continue
+		}
+
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Compares two numbers, strings or time.Times, returning -1, 0 or 1 (like strings.Compare).  The
+// second return value is false if the values can't be compared.
+func compareValues(a, b reflect.Value) (int, bool) {
+	a, b = reflect.Indirect(a), reflect.Indirect(b)
+	if !a.IsValid() || !b.IsValid() {
+		return 0, false
+	}
+
+	if a.Type() == timeType && b.Type() == timeType {
+		return a.Interface().(time.Time).Compare(b.Interface().(time.Time)), true
+	}
+
+	switch {
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return strings.Compare(a.String(), b.String()), true
+
+	case isIntKind(a.Kind()) && isIntKind(b.Kind()):
+		return cmpOrdered(a.Int(), b.Int()), true
+
+	case isUintKind(a.Kind()) && isUintKind(b.Kind()):
+		return cmpOrdered(a.Uint(), b.Uint()), true
+	}
+
+	fa, aok := toFloat(a)
+	fb, bok := toFloat(b)
+	if !aok || !bok {
+		return 0, false
+	}
+	return cmpOrdered(fa, fb), true
+}
+
+func cmpOrdered[T int64 | uint64 | float64](a, b T) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func toFloat(v reflect.Value) (float64, bool) {
+	switch {
+	case isIntKind(v.Kind()):
+		return float64(v.Int()), true
+	case isUintKind(v.Kind()):
+		return float64(v.Uint()), true
+	case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+func isIntKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUintKind(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
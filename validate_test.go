@@ -0,0 +1,146 @@
+package structomancer_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/brynbellomy/go-structomancer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Validate", func() {
+	type Contact struct {
+		Email   string `weezy:"email, email"`
+		Website string `weezy:"website, url, omitempty"`
+	}
+
+	type Account struct {
+		Name     string             `xyzzy:"name, min=2, max=8"`
+		Age      int                `xyzzy:"age, min=18"`
+		Code     string             `xyzzy:"code, len=4, pattern=^[A-Z]+$"`
+		Plan     string             `xyzzy:"plan, oneof=free|pro"`
+		Tags     []string           `xyzzy:"tags, max=2"`
+		Score    *float64           `xyzzy:"score, max=1"`
+		Contacts []Contact          `xyzzy:"contacts, @tag=weezy"`
+		ByName   map[string]Contact `xyzzy:"byName, @tag=weezy"`
+	}
+
+	z := structomancer.New(&Account{}, tagName)
+
+	It("should return nil when every rule passes", func() {
+		a := &Account{
+			Name:     "keith",
+			Age:      30,
+			Code:     "ABCD",
+			Plan:     "pro",
+			Contacts: []Contact{{Email: "keith@example.com", Website: "https://example.com"}},
+		}
+		Expect(z.Validate(a)).To(Succeed())
+	})
+
+	It("should list every violation, with its path, at every nesting level", func() {
+		score := 1.5
+		a := Account{
+			Name:     "k",
+			Age:      12,
+			Code:     "abc",
+			Plan:     "enterprise",
+			Tags:     []string{"a", "b", "c"},
+			Score:    &score,
+			Contacts: []Contact{{Email: "keith@example.com"}, {Email: "nope", Website: "/relative"}},
+			ByName:   map[string]Contact{"ron": {Email: "Ron <ron@example.com>"}},
+		}
+
+		err := z.Validate(a)
+
+		var violations structomancer.ValidationErrors
+		Expect(errors.As(err, &violations)).To(BeTrue())
+
+		var failed []string
+		for _, v := range violations {
+			failed = append(failed, v.Path+" "+v.Rule)
+		}
+		Expect(failed).To(ConsistOf(
+			"name min",
+			"age min",
+			"code len",
+			"code pattern",
+			"plan oneof",
+			"tags max",
+			"score max",
+			"contacts[1].email email",
+			"contacts[1].website url",
+			"byName[ron].email email",
+		))
+	})
+
+	It("should return a NilStructError or UnsupportedTypeError for arguments that aren't structs", func() {
+		var nilErr *structomancer.NilStructError
+		Expect(errors.As(z.Validate(nil), &nilErr)).To(BeTrue())
+		Expect(errors.As(z.Validate((*Account)(nil)), &nilErr)).To(BeTrue())
+
+		var unsupportedErr *structomancer.UnsupportedTypeError
+		Expect(errors.As(z.Validate(5), &unsupportedErr)).To(BeTrue())
+		Expect(unsupportedErr.Op).To(Equal("Validate"))
+	})
+
+	It("should panic when a rule doesn't make sense for the field's type", func() {
+		type Bad struct {
+			N int `xyzzy:"n, len=3"`
+		}
+		Expect(func() { structomancer.New(Bad{}, tagName) }).To(Panic())
+	})
+
+	Context("with cross-field rules", func() {
+		type Window struct {
+			Start time.Time `xyzzy:"start"`
+			End   time.Time `xyzzy:"end, gtfield=start"`
+			Min   int       `xyzzy:"min"`
+			Max   int       `xyzzy:"max, gtefield=min"`
+			Mode  string    `xyzzy:"mode, oneof=api|web"`
+			Token string    `xyzzy:"token, omitempty, required_if=mode:api, excluded_if=mode:web"`
+			Proxy string    `xyzzy:"proxy, omitempty"`
+			Port  int       `xyzzy:"port, omitempty, required_with=proxy"`
+		}
+
+		z := structomancer.New(&Window{}, tagName)
+		now := time.Now()
+
+		It("should return nil when every rule passes", func() {
+			w := &Window{Start: now, End: now.Add(time.Hour), Min: 1, Max: 1, Mode: "api", Token: "t"}
+			Expect(z.Validate(w)).To(Succeed())
+		})
+
+		It("should report violations naming both fields involved", func() {
+			w := &Window{Start: now, End: now.Add(-time.Hour), Min: 2, Max: 1, Mode: "api", Proxy: "p"}
+
+			var violations structomancer.ValidationErrors
+			Expect(errors.As(z.Validate(w), &violations)).To(BeTrue())
+
+			var failed []string
+			for _, v := range violations {
+				failed = append(failed, v.Path+" "+v.Rule+" "+v.OtherPath)
+			}
+			Expect(failed).To(ConsistOf(
+				"end gtfield start",
+				"max gtefield min",
+				"token required_if mode",
+				"port required_with proxy",
+			))
+
+			w = &Window{Start: now, End: now.Add(time.Hour), Mode: "web", Token: "t"}
+			Expect(errors.As(z.Validate(w), &violations)).To(BeTrue())
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].Rule).To(Equal("excluded_if"))
+		})
+
+		It("should panic when a rule references an unknown sibling", func() {
+			type Bad struct {
+				N int `xyzzy:"n, gtfield=nope"`
+			}
+			Expect(func() { structomancer.New(Bad{}, tagName) }).To(Panic())
+		})
+	})
+})
@@ -0,0 +1,58 @@
+package structomancer
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Coerces the scalar `nv` into a `destType` when the WeaklyTypedInput decode option is set: strings
+// are parsed into numbers and bools, numbers and bools are formatted as strings, and numbers and
+// bools are converted into each other.  Returns false if no coercion applies.
+func (d *decodeState) weakScalar(nv reflect.Value, destType reflect.Type) (reflect.Value, bool, error) {
+	destKind := destType.Kind()
+
+	switch {
+	case nv.Kind() == reflect.String && destKind != reflect.String:
+		s := strings.TrimSpace(nv.String())
+		if s == "" {
+			return reflect.Zero(destType), true, nil
+		}
+
+		v, err := parseString(s, destType)
+		if err != nil {
+			return reflect.Value{}, true, d.fail(&ConversionError{Path: d.currentPath(), From: nv.Type(), To: destType})
+		}
+		return v, true, nil
+
+	case nv.Kind() == reflect.Bool && destKind == reflect.String:
+		return reflect.ValueOf(strconv.FormatBool(nv.Bool())).Convert(destType), true, nil
+
+	case nv.Kind() == reflect.Bool && isNumberKind(destKind):
+		n := 0
+		if nv.Bool() {
+			n = 1
+		}
+		return reflect.ValueOf(n).Convert(destType), true, nil
+
+	case isNumberKind(nv.Kind()) && destKind == reflect.String:
+		return reflect.ValueOf(formatNumber(nv)).Convert(destType), true, nil
+
+	case isNumberKind(nv.Kind()) && destKind == reflect.Bool:
+		return reflect.ValueOf(!nv.IsZero()).Convert(destType), true, nil
+	}
+	return reflect.Value{}, false, nil
+}
+
+// Formats a numeric value in base 10, using the shortest representation that round-trips for
+// floats.
+func formatNumber(v reflect.Value) string {
+	switch {
+	case isIntKind(v.Kind()):
+		return strconv.FormatInt(v.Int(), 10)
+	case isUintKind(v.Kind()):
+		return strconv.FormatUint(v.Uint(), 10)
+	default:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())
+	}
+}
@@ -0,0 +1,110 @@
+package structomancer_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/brynbellomy/go-structomancer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Weakly typed decoding", func() {
+	type Query struct {
+		Page    int           `xyzzy:"page"`
+		Limit   uint8         `xyzzy:"limit"`
+		Ratio   float64       `xyzzy:"ratio"`
+		Verbose bool          `xyzzy:"verbose"`
+		Strict  bool          `xyzzy:"strict"`
+		Label   string        `xyzzy:"label"`
+		Flag    string        `xyzzy:"flag"`
+		Count   int           `xyzzy:"count"`
+		Tags    []string      `xyzzy:"tags"`
+		IDs     []int         `xyzzy:"ids"`
+		Timeout time.Duration `xyzzy:"timeout"`
+		Since   time.Time     `xyzzy:"since, format=unix"`
+		Data    []byte        `xyzzy:"data"`
+	}
+
+	weak := structomancer.DecodeOptions{WeaklyTypedInput: true}
+
+	It("should coerce between strings, numbers and bools", func() {
+		z := structomancer.New(&Query{}, "xyzzy")
+		z.SetDecodeOptions(weak)
+
+		s, err := z.MapToStruct(map[string]interface{}{
+			"page":    "3",
+			"limit":   " 50 ",
+			"ratio":   "0.25",
+			"verbose": "true",
+			"strict":  1,
+			"label":   65,
+			"flag":    true,
+			"count":   true,
+			"tags":    "solo",
+			"ids":     []interface{}{"1", 2},
+			"timeout": "1m",
+			"since":   "1614834367",
+			"data":    "raw",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s).To(Equal(&Query{
+			Page:    3,
+			Limit:   50,
+			Ratio:   0.25,
+			Verbose: true,
+			Strict:  true,
+			Label:   "65",
+			Flag:    "true",
+			Count:   1,
+			Tags:    []string{"solo"},
+			IDs:     []int{1, 2},
+			Timeout: time.Minute,
+			Since:   time.Unix(1614834367, 0),
+			Data:    []byte("raw"),
+		}))
+	})
+
+	It("should format floats without losing precision or adding zeroes", func() {
+		z := structomancer.New(&Query{}, "xyzzy")
+		z.SetDecodeOptions(weak)
+
+		s, err := z.MapToStruct(map[string]interface{}{"label": 1.5, "flag": float32(0.1)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.(*Query).Label).To(Equal("1.5"))
+		Expect(s.(*Query).Flag).To(Equal("0.1"))
+	})
+
+	It("should return a ConversionError for strings that can't be parsed", func() {
+		z := structomancer.New(&Query{}, "xyzzy")
+		z.SetDecodeOptions(weak)
+
+		_, err := z.MapToStruct(map[string]interface{}{"limit": "300"})
+		var convErr *structomancer.ConversionError
+		Expect(errors.As(err, &convErr)).To(BeTrue())
+		Expect(convErr.Path).To(Equal("limit"))
+	})
+
+	Context("when the option isn't set", func() {
+		It("should refuse to coerce strings into numbers or single values into slices", func() {
+			z := structomancer.New(&Query{}, "xyzzy")
+
+			var convErr *structomancer.ConversionError
+			_, err := z.MapToStruct(map[string]interface{}{"page": "3"})
+			Expect(errors.As(err, &convErr)).To(BeTrue())
+
+			_, err = z.MapToStruct(map[string]interface{}{"tags": "solo"})
+			Expect(errors.As(err, &convErr)).To(BeTrue())
+		})
+
+		It("should refuse to convert integers into strings as runes", func() {
+			z := structomancer.New(&Query{}, "xyzzy")
+			_, err := z.MapToStruct(map[string]interface{}{"label": 65})
+
+			var convErr *structomancer.ConversionError
+			Expect(errors.As(err, &convErr)).To(BeTrue())
+			Expect(convErr.Path).To(Equal("label"))
+		})
+	})
+})